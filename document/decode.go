@@ -0,0 +1,91 @@
+package document
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/campfhir/wsv/internal"
+)
+
+var (
+	ErrStructMismatch = errors.New("the struct does not match the document headers")
+	ErrDecodeType     = errors.New("can only decode a line into a struct or a pointer to a struct")
+)
+
+// An option to alter the behavior of [CheckStructMatches]
+type CheckOption func(opts *checkOptions)
+
+type checkOptions struct {
+	requireAllFields bool
+}
+
+// Also fail when a struct field with a `wsv` tag does not have a matching header, comment fields and fields
+// ignored with `-` are not required
+func CheckRequireAllFields() CheckOption {
+	return func(opts *checkOptions) {
+		opts.requireAllFields = true
+	}
+}
+
+// Verifies every header of the document is matched to a field of the struct T, by the `wsv` tag or the field name
+// the same as [DecodeLine], to catch schema mismatches before decoding. With [CheckRequireAllFields] every struct
+// field with a `wsv` tag must also have a matching header.
+//
+// Returns an error wrapping ErrStructMismatch naming the unmatched headers and struct fields.
+func CheckStructMatches[T any](doc *Document, options ...CheckOption) error {
+	opts := checkOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return errors.New("expected a struct to check the headers against")
+	}
+	msgs := make([]string, 0)
+	if unmatched := internal.UnmatchedColumns(t, doc.Headers()); len(unmatched) > 0 {
+		msgs = append(msgs, fmt.Sprintf("headers without a struct field: %s", strings.Join(internal.SerializeValues(unmatched), ", ")))
+	}
+	if opts.requireAllFields {
+		if missing := internal.MissingColumns(t, doc.Headers()); len(missing) > 0 {
+			msgs = append(msgs, fmt.Sprintf("struct fields without a header: %s", strings.Join(internal.SerializeValues(missing), ", ")))
+		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%w: %s", ErrStructMismatch, strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// DecodeLine maps the fields of a single line into the struct T, matching fields to struct fields by the `wsv` tag
+// the same way as reader.Unmarshal. Useful to selectively decode lines while iterating over [Document.Lines].
+// T can also be a pointer to a struct, which is allocated for the line.
+//
+// The fields of the line are matched by their field name, so the line needs to belong to a document with headers.
+//
+// Returns ErrDecodeType if T is not a struct or a pointer to a struct.
+func DecodeLine[T any](line Line) (T, error) {
+	var v T
+	t := reflect.TypeFor[T]()
+	pointer := t.Kind() == reflect.Pointer
+	if pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return v, ErrDecodeType
+	}
+	val, err := internal.UnmarshalRow(line.Fields(), t, internal.RowOptions{})
+	if err != nil {
+		return v, err
+	}
+	if pointer {
+		p := reflect.New(t)
+		p.Elem().Set(*val)
+		return p.Interface().(T), nil
+	}
+	return val.Interface().(T), nil
+}
@@ -2,9 +2,11 @@
 package document
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"slices"
 	"strconv"
 	"strings"
@@ -21,6 +23,8 @@ type WriteError struct {
 	expectedFieldCount int
 	err                error
 	fieldValues        []internal.Field
+	// the control character found with ErrControlCharacter
+	control rune
 }
 
 var (
@@ -32,6 +36,12 @@ var (
 	ErrFieldCount                   = errors.New("wrong number of fields")
 	ErrCannotSortNonTabularDocument = errors.New("the document is non-tabular and cannot be sorted")
 	ErrFieldNotFoundForSortBy       = errors.New("the field was not found")
+	ErrNonTabularDocument           = errors.New("the document is non-tabular")
+	ErrInvalidColumnWidth           = errors.New("column width must not be negative")
+	ErrDuplicateKey                 = errors.New("duplicate key")
+	ErrControlCharacter             = errors.New("value contains a control character")
+	ErrInvalidNumberFormat          = errors.New("format does not have a single number verb")
+	ErrEmptyRawValue                = errors.New("value of a raw column is empty")
 )
 
 func (e *WriteError) Error() string {
@@ -50,14 +60,60 @@ func (e *WriteError) Error() string {
 		}), "  "))
 	}
 
+	if e.err == ErrControlCharacter {
+		return fmt.Sprintf("field %d of line %d contains the control character %U", e.fieldIndex, e.line, e.control)
+	}
+
+	if e.err == ErrEmptyRawValue {
+		return fmt.Sprintf("field %d of line %d is empty in a raw column and would not be read back", e.fieldIndex, e.line)
+	}
+
 	return e.err.Error()
 }
 
+func (e *WriteError) Unwrap() error {
+	return e.err
+}
+
 type Document struct {
-	Tabular          bool
-	EmitHeaders      bool
+	Tabular     bool
+	EmitHeaders bool
+	// Escape double quotes within values with a backslash `\"` instead of doubling them `""` when writing
+	BackslashEscapedQuotes bool
+	// Write a UTF-8 byte order mark at the start of the document
+	EmitBOM bool
+	// Wrap lines wider than this many runes when writing, continuing the overflowing fields on the next visual line
+	// after an indent marker. Wrapped output is for display and cannot be read back, 0 does not wrap
+	MaxLineWidth int
+	// Reports whether a rune separates fields, nil uses the whitespace characters of the WSV specification.
+	// Padding must be a delimiter and values containing a delimiter are written in double quotes
+	IsDelimiter func(r rune) bool
+	// The token written for null values instead of `-`, empty uses `-`. Values equal to the token are quoted and a
+	// leading `-` is only quoted with the default token. [Document.AppendValues] reads the token as null
+	NullString string
+	// How values containing control characters other than the line feed, such as `\t`, `\v` or `\x00`, are written.
+	// The default writes them as is, which can break the alignment of the columns
+	ControlChars ControlChars
+	// Align the comments of every line with fields to the same column when writing, padding the lines before the comment
+	// to the width of the widest line with a comment. Comment-only lines are not indented
+	AlignComments bool
+	// Write the lines given a format with [Document.SetLineFormat], such as by a reader with `PreserveFormatting` set,
+	// with the spacing and field text they were read with instead of aligning the columns. Fields whose value changed
+	// are serialized in place and lines whose number of fields changed are aligned as usual. Lines end with a line feed.
+	PreserveFormatting bool
+	// Write `GroupSeparator` on its own line between every group of this many data lines, 0 does not group
+	GroupRows int
+	// The line written between groups of `GroupRows` data lines, an empty string writes a blank line.
+	// Use a comment such as `# ---` for the output to be read back
+	GroupSeparator string
+	// the number of data lines written, to separate the groups of `GroupRows`
+	dataLinesWritten int
+	// the original format of lines, see [Document.SetLineFormat]
+	formats          map[Line]preservedLine
 	lines            []Line
 	maxColumnWidth   map[int]int
+	minColumnWidth   map[int]int
+	alignment        map[int]Alignment
 	padding          []rune
 	currentWriteLine int
 	currentField     int
@@ -65,11 +121,28 @@ type Document struct {
 	headers          []string
 	headerLine       int
 	hasHeaders       bool
+	cellFormatter    CellFormatter
+	quoting          Quoting
+	// column widths of the serialized cells, calculated at the start of writing when the serialization is customized
+	formattedWidths map[int]int
+	// the width of the widest line with a comment before the comment, calculated at the start of writing when `AlignComments` is set
+	commentColumn int
+	// lines kept at the top of the document, after the header, when sorting
+	pinned map[Line]bool
+	// the headers of the columns written first, see [Document.SetColumnOrder]
+	columnOrder []string
+	// the indexes of the columns in the order they are written, resolved from `columnOrder` at the start of writing
+	writeOrder []int
+	// the number formats of columns, see [Document.SetColumnFormat]
+	columnFormats map[int]string
+	// the columns written verbatim, see [Document.SetRawColumn]
+	rawColumns map[int]bool
 }
 
 func (doc *Document) SetPadding(rs []rune) error {
+	isDelimiter := doc.serializeOptions().IsDelimiter
 	for _, r := range rs {
-		if !internal.IsFieldDelimiter(r) {
+		if !isDelimiter(r) {
 			return &WriteError{err: ErrInvalidPaddingRune}
 		}
 	}
@@ -96,7 +169,7 @@ func Null() appendLineField {
 
 // Adds a line to a document and then appends values to the line added
 //
-// the literally "-" will be interpreded as null,  if you need a literal "-" use the `line.Append(val string)` function
+// the literally "-", or the `NullString` of the document, will be interpreded as null,  if you need a literal "-" use the `line.Append(val string)` function
 //
 // returns the line that was added, can return an error due validation errors
 func (doc *Document) AppendValues(vals ...string) (Line, error) {
@@ -105,11 +178,12 @@ func (doc *Document) AppendValues(vals ...string) (Line, error) {
 		return nil, err
 	}
 	for _, val := range vals {
-		if val == "-" {
+		if val == doc.nullString() {
 			err := line.AppendNull()
 			if err != nil {
 				return line, err
 			}
+			continue
 		}
 		err := line.Append(val)
 		if err != nil {
@@ -159,53 +233,86 @@ func (doc *Document) AddLine() (Line, error) {
 // evaluates previous and current record fields and should return true if current field is after previous field
 type SortFunc = func(prv *internal.Field, curr *internal.Field) bool
 
-func Sort(fieldName string) *internal.SortOption {
-	return &internal.SortOption{FieldName: fieldName}
+// A sort of the lines by a field, built with [Sort] and the other Sort functions, applied with [Document.SortBy]
+// or passed to [MarshalWithOptions] to sort the marshalled document
+type SortOption internal.SortOption
+
+// adds the sort to the sorts applied to the marshalled document
+func (o *SortOption) apply(opts *marshalOptions) {
+	if o != nil {
+		opts.sorts = append(opts.sorts, o)
+	}
+}
+
+func Sort(fieldName string) *SortOption {
+	return &SortOption{FieldName: fieldName}
 }
 
-func SortDesc(fieldName string) *internal.SortOption {
-	return &internal.SortOption{
+func SortDesc(fieldName string) *SortOption {
+	return &SortOption{
 		FieldName: fieldName,
 		Desc:      true,
 	}
 }
 
-func SortNumber(fieldName string) *internal.SortOption {
-	return &internal.SortOption{FieldName: fieldName, AsNumber: true, NumberRadix: 10}
+func SortNumber(fieldName string) *SortOption {
+	return &SortOption{FieldName: fieldName, AsNumber: true, NumberRadix: 10}
+}
+
+func SortNumberDesc(fieldName string) *SortOption {
+	return &SortOption{FieldName: fieldName, AsNumber: true, Desc: true, NumberRadix: 10}
 }
 
-func SortNumberDesc(fieldName string) *internal.SortOption {
-	return &internal.SortOption{FieldName: fieldName, AsNumber: true, Desc: true, NumberRadix: 10}
+func SortNumberBase(fieldName string, base int) *SortOption {
+	return &SortOption{FieldName: fieldName, AsNumber: true, NumberRadix: base}
 }
 
-func SortNumberBase(fieldName string, base int) *internal.SortOption {
-	return &internal.SortOption{FieldName: fieldName, AsNumber: true, NumberRadix: base}
+func SortNumberBaseDesc(fieldName string, base int) *SortOption {
+	return &SortOption{FieldName: fieldName, AsNumber: true, Desc: true, NumberRadix: base}
 }
 
-func SortNumberBaseDesc(fieldName string, base int) *internal.SortOption {
-	return &internal.SortOption{FieldName: fieldName, AsNumber: true, Desc: true, NumberRadix: base}
+func SortTime(fieldName string, format string) *SortOption {
+	return &SortOption{FieldName: fieldName, AsTime: true, TimeFormat: format}
 }
 
-func SortTime(fieldName string, format string) *internal.SortOption {
-	return &internal.SortOption{FieldName: fieldName, AsTime: true, TimeFormat: format}
+func SortTimeDesc(fieldName string, format string) *SortOption {
+	return &SortOption{FieldName: fieldName, AsTime: true, Desc: true, TimeFormat: format}
 }
 
-func SortTimeDesc(fieldName string, format string) *internal.SortOption {
-	return &internal.SortOption{FieldName: fieldName, AsTime: true, Desc: true, TimeFormat: format}
+func SortDuration(fieldName string) *SortOption {
+	return &SortOption{FieldName: fieldName, AsDuration: true}
 }
 
-func SortDuration(fieldName string) *internal.SortOption {
-	return &internal.SortOption{FieldName: fieldName, AsDuration: true}
+func SortDurationDesc(fieldName string) *SortOption {
+	return &SortOption{FieldName: fieldName, AsDuration: true, Desc: true}
 }
 
-func SortDurationDesc(fieldName string) *internal.SortOption {
-	return &internal.SortOption{FieldName: fieldName, AsDuration: true, Desc: true}
+// Pins the lines, 1-indexed, so they stay at the top of the document after the header when sorted with [Document.SortBy],
+// such as keeping a totals or summary row in place. Pinned lines keep their order relative to each other.
+//
+// Returns ErrLineNotFound if any of the lines do not exist, in which case no lines are pinned.
+func (doc *Document) PinRows(lineNumbers ...int) error {
+	lines := make([]Line, 0, len(lineNumbers))
+	for _, ln := range lineNumbers {
+		line, err := doc.Line(ln)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line)
+	}
+	if doc.pinned == nil {
+		doc.pinned = make(map[Line]bool)
+	}
+	for _, line := range lines {
+		doc.pinned[line] = true
+	}
+	return nil
 }
 
-// Sorts the documents lines in place based on the sort options
+// Sorts the documents lines in place based on the sort options, lines pinned with [Document.PinRows] stay at the top
 //
 // Will sort until finished or a field specified is not found, in which case a ErrFieldNotFoundForSortBy is returned
-func (doc *Document) SortBy(sortOptions ...*internal.SortOption) error {
+func (doc *Document) SortBy(sortOptions ...*SortOption) error {
 	if !doc.Tabular {
 		return ErrCannotSortNonTabularDocument
 	}
@@ -218,14 +325,8 @@ func (doc *Document) SortBy(sortOptions ...*internal.SortOption) error {
 			continue
 		}
 		slices.SortStableFunc(doc.lines, func(cur Line, next Line) int {
-			if cur.IsHeader() && next.IsHeader() {
-				return 0
-			}
-			if cur.IsHeader() {
-				return -1
-			}
-			if next.IsHeader() {
-				return +1
+			if order, placed := doc.comparePlacement(cur, next); placed {
+				return order
 			}
 			a, err := cur.FieldByName(sort.FieldName)
 			if err != nil {
@@ -241,7 +342,7 @@ func (doc *Document) SortBy(sortOptions ...*internal.SortOption) error {
 				// }
 				return -1
 			}
-			return sortFieldsColumn(sort, a, b)
+			return sortFieldsColumn((*internal.SortOption)(sort), a, b)
 		})
 
 	}
@@ -249,6 +350,50 @@ func (doc *Document) SortBy(sortOptions ...*internal.SortOption) error {
 	return nil
 }
 
+// Sorts the lines after the header in place with `cmp`, which returns a negative number when a sorts before b, a positive
+// number when a sorts after b and 0 to keep their order, such as for a composite key. Lines pinned with [Document.PinRows]
+// stay at the top and the lines are renumbered afterwards. `cmp` is also called with blank and comment-only lines.
+//
+// Returns ErrCannotSortNonTabularDocument for a non-tabular document the same as [Document.SortBy]
+func (doc *Document) SortByFunc(cmp func(a, b Line) int) error {
+	if !doc.Tabular {
+		return ErrCannotSortNonTabularDocument
+	}
+	slices.SortStableFunc(doc.lines, func(cur Line, next Line) int {
+		if order, placed := doc.comparePlacement(cur, next); placed {
+			return order
+		}
+		return cmp(cur, next)
+	})
+	doc.ReIndexLineNumbers()
+	return nil
+}
+
+// Orders the header and pinned lines before the other lines when sorting, `placed` is false when neither line is
+// the header or pinned and the lines are compared by their fields
+func (doc *Document) comparePlacement(cur Line, next Line) (order int, placed bool) {
+	if cur.IsHeader() && next.IsHeader() {
+		return 0, true
+	}
+	if cur.IsHeader() {
+		return -1, true
+	}
+	if next.IsHeader() {
+		return +1, true
+	}
+	if doc.pinned[cur] || doc.pinned[next] {
+		switch {
+		case doc.pinned[cur] && doc.pinned[next]:
+			return 0, true
+		case doc.pinned[cur]:
+			return -1, true
+		default:
+			return +1, true
+		}
+	}
+	return 0, false
+}
+
 // Compare compares the line with another line for sorting
 // returns
 //
@@ -258,6 +403,10 @@ func (doc *Document) SortBy(sortOptions ...*internal.SortOption) error {
 //	 0 when line[Field].Value == cmpLine[Field].Value
 //	+1 when line[Field].Value > cmpLine[Field].Value or cmpLine[Field].Value is nil
 func sortFieldsColumn(opt *internal.SortOption, a *internal.Field, b *internal.Field) int {
+	if opt.NullAs != "" {
+		a = nullAs(a, opt.NullAs)
+		b = nullAs(b, opt.NullAs)
+	}
 	if opt.AsDuration {
 		order := 0
 		if a == nil || a.IsNull {
@@ -314,6 +463,14 @@ func sortFieldsColumn(opt *internal.SortOption, a *internal.Field, b *internal.F
 	return order
 }
 
+// substitutes a null field with a field holding the value
+func nullAs(field *internal.Field, value string) *internal.Field {
+	if field != nil && !field.IsNull {
+		return field
+	}
+	return &internal.Field{Value: value}
+}
+
 func sortNumbers(radix int, a string, b string) int {
 	number1, err := strconv.ParseInt(a, radix, strconv.IntSize)
 	if err != nil {
@@ -432,8 +589,269 @@ func (doc *Document) WriteLine(n int, includeHeader bool) ([]byte, error) {
 	return []byte(strings.Join(dataLine, string(doc.padding))), nil
 }
 
+// overrides the serialized text of a cell when writing, line is 1-indexed and col is 0-indexed
+type CellFormatter = func(line int, col int, field *internal.Field) string
+
+// Sets a function consulted by [Document.Write] to override the serialized text of each cell, including the header line,
+// without changing the underlying data. Columns are aligned using the width of the formatted text.
+//
+// Setting the formatter to nil restores the default serialization.
+func (doc *Document) SetCellFormatter(fn CellFormatter) {
+	doc.cellFormatter = fn
+}
+
+// The serialized text of a cell, using the cell formatter if one is set
+func (doc *Document) cellText(line Line, col int, field *internal.Field) string {
+	if doc.cellFormatter != nil {
+		return doc.cellFormatter(line.LineNumber(), col, field)
+	}
+	if doc.rawColumns[col] && !field.IsNull && !line.IsHeader() {
+		return field.Value
+	}
+	if format, ok := doc.columnFormats[col]; ok && !field.IsNull && !line.IsHeader() {
+		if v, ok := internal.FormatNumber(format, field.Value); ok {
+			formatted := *field
+			formatted.Value = v
+			return formatted.SerializeTextWith(doc.serializeOptions())
+		}
+	}
+	return field.SerializeTextWith(doc.serializeOptions())
+}
+
+func (doc *Document) serializeOptions() internal.SerializeOptions {
+	isDelimiter := doc.IsDelimiter
+	if isDelimiter == nil {
+		isDelimiter = internal.IsFieldDelimiter
+	}
+	return internal.SerializeOptions{BackslashEscape: doc.BackslashEscapedQuotes, IsDelimiter: isDelimiter, Quoting: doc.quoting, Null: doc.NullString, EscapeControlChars: doc.ControlChars == ControlCharsEscape}
+}
+
+func (doc *Document) nullString() string {
+	if doc.NullString != "" {
+		return doc.NullString
+	}
+	return "-"
+}
+
+// returns true when cells may serialize to a different width than the one calculated when the field was added
+func (doc *Document) customSerialization() bool {
+	return doc.cellFormatter != nil || doc.BackslashEscapedQuotes || doc.IsDelimiter != nil || doc.quoting != QuoteMinimal || doc.NullString != "" || doc.ControlChars == ControlCharsEscape || len(doc.columnFormats) > 0 || len(doc.rawColumns) > 0
+}
+
+// When non-null values are wrapped in double quotes when writing
+type Quoting = internal.Quoting
+
+const (
+	// Only quote values that need quoting to be read back, the default
+	QuoteMinimal = internal.QuoteMinimal
+	// Quote every non-null value
+	QuoteAll = internal.QuoteAll
+	// Quote every non-null value that does not parse as a number
+	QuoteNonNumeric = internal.QuoteNonNumeric
+)
+
+// Sets when values are wrapped in double quotes when writing, including the header line. Null values are always written as the null token.
+func (doc *Document) SetQuoting(q Quoting) {
+	doc.quoting = q
+}
+
+// How control characters within values are written, see `Document.ControlChars`
+type ControlChars int
+
+const (
+	// Write control characters as is, the default
+	ControlCharsKeep ControlChars = iota
+	// Return a [WriteError] wrapping ErrControlCharacter for a line with a control character in a value
+	ControlCharsReject
+	// Write control characters as `\uHHHH` and backslashes as `\\`, read back by a reader with `UnescapeControlChars` set
+	ControlCharsEscape
+)
+
+// returns a [WriteError] for the first field of line with a control character
+func (doc *Document) checkControlChars(line Line) error {
+	for i, field := range line.Fields() {
+		if field.IsNull {
+			continue
+		}
+		if at := strings.IndexFunc(field.Value, internal.IsControlChar); at >= 0 {
+			r, _ := utf8.DecodeRuneInString(field.Value[at:])
+			return &WriteError{line: line.LineNumber(), fieldIndex: i, err: ErrControlCharacter, control: r}
+		}
+	}
+	return nil
+}
+
+// returns an error for an empty value in a raw column of a data line, which would be written as nothing
+func (doc *Document) checkRawColumns(line Line) error {
+	if line.IsHeader() {
+		return nil
+	}
+	for i, field := range line.Fields() {
+		if doc.rawColumns[i] && !field.IsNull && field.Value == "" {
+			return &WriteError{line: line.LineNumber(), fieldIndex: i, err: ErrEmptyRawValue}
+		}
+	}
+	return nil
+}
+
+// The width a column is padded to when writing
+func (doc *Document) writeWidth(col int) (int, error) {
+	if !doc.customSerialization() {
+		return doc.MaxColumnWidth(col)
+	}
+	v, ok := doc.formattedWidths[col]
+	if !ok {
+		return 0, ErrFieldIndexedNotFound
+	}
+	return v, nil
+}
+
+// Calculates the widths of the serialized cells before writing
+func (doc *Document) calculateFormattedWidths() {
+	if !doc.customSerialization() {
+		return
+	}
+	doc.formattedWidths = make(map[int]int)
+	for _, line := range doc.lines {
+		for i, field := range line.Fields() {
+			w := utf8.RuneCountInString(doc.cellText(line, i, &field))
+			if w > doc.formattedWidths[i] {
+				doc.formattedWidths[i] = w
+			}
+		}
+	}
+}
+
+// The original text of a line, the white space before each field, the text of each field as it was read and the text
+// after the last field
+type LineFormat = internal.LineFormat
+
+// the format of a line along with the fields and comment it was read with
+type preservedLine struct {
+	format  LineFormat
+	fields  []internal.Field
+	comment string
+}
+
+// Records the original text of the line, written in place of the aligned line when `PreserveFormatting` is set.
+// The current fields and comment of the line are compared to the ones when writing to detect edits.
+func (doc *Document) SetLineFormat(line Line, format LineFormat) {
+	if doc.formats == nil {
+		doc.formats = make(map[Line]preservedLine)
+	}
+	doc.formats[line] = preservedLine{format: format, fields: slices.Clone(line.Fields()), comment: line.Comment()}
+}
+
+// Appends the line with its original format, returns false if the line does not have a format or its fields were added or removed
+func (doc *Document) appendPreserved(buf []byte, line Line) ([]byte, bool) {
+	p, ok := doc.formats[line]
+	fields := line.Fields()
+	if !doc.PreserveFormatting || !ok || len(fields) != len(p.format.Raw) || len(doc.writeOrder) > 0 {
+		return buf, false
+	}
+	for i, field := range fields {
+		buf = append(buf, p.format.Spacing[i]...)
+		if field.Value == p.fields[i].Value && field.IsNull == p.fields[i].IsNull {
+			buf = append(buf, p.format.Raw[i]...)
+			continue
+		}
+		buf = append(buf, doc.cellText(line, i, &field)...)
+	}
+	switch {
+	case line.Comment() == p.comment:
+		buf = append(buf, p.format.Tail...)
+	case line.Comment() != "" && len(fields) > 0:
+		buf = append(buf, internal.RuneToBytes(doc.padding)...)
+		buf = append(buf, "#"+line.Comment()...)
+	case line.Comment() != "":
+		buf = append(buf, "#"+line.Comment()...)
+	}
+	return buf, true
+}
+
+// Calculates the column comments are aligned to before writing
+func (doc *Document) calculateCommentColumn() {
+	doc.commentColumn = 0
+	if !doc.AlignComments {
+		return
+	}
+	for _, line := range doc.lines {
+		if line.Comment() != "" && line.FieldCount() > 0 {
+			doc.commentColumn = max(doc.commentColumn, doc.fieldsWidth(line))
+		}
+	}
+}
+
+// The width of the fields of a line when written, not including the comment
+func (doc *Document) fieldsWidth(line Line) int {
+	w := 0
+	fields := line.Fields()
+	for pos, i := range doc.fieldOrder(len(fields)) {
+		field := fields[i]
+		p := utf8.RuneCountInString(doc.cellText(line, i, &field))
+		if doc.Tabular && len(fields)-1 != pos {
+			mw, err := doc.writeWidth(i)
+			if err != nil {
+				doc.CalculateMaxFieldLengths()
+				mw, _ = doc.writeWidth(i)
+			}
+			p = max(p, mw, doc.minColumnWidth[i])
+		}
+		if pos > 0 {
+			w += len(doc.padding)
+		}
+		w += p
+	}
+	return w
+}
+
+// Writes the columns with the headers `names` first, in the order of `names`, followed by the other columns in their
+// current order. Only the written order changes, the columns of the document keep their order. Calling it without
+// any names writes the columns in their current order again.
+//
+// Returns ErrFieldNameNotFound if there is no column for one of the `names`.
+func (doc *Document) SetColumnOrder(names ...string) error {
+	for _, name := range names {
+		if !slices.Contains(doc.headers, name) {
+			return fmt.Errorf("%w: %s", ErrFieldNameNotFound, name)
+		}
+	}
+	doc.columnOrder = slices.Clone(names)
+	return nil
+}
+
+// Resolves the headers of [Document.SetColumnOrder] to column indexes, headers renamed or deleted since are skipped
+func (doc *Document) calculateWriteOrder() {
+	doc.writeOrder = nil
+	for _, name := range doc.columnOrder {
+		col := slices.Index(doc.headers, name)
+		if col >= 0 && !slices.Contains(doc.writeOrder, col) {
+			doc.writeOrder = append(doc.writeOrder, col)
+		}
+	}
+}
+
+// The indexes of the `n` fields of a line in the order they are written
+func (doc *Document) fieldOrder(n int) []int {
+	order := make([]int, 0, n)
+	for _, col := range doc.writeOrder {
+		if col < n {
+			order = append(order, col)
+		}
+	}
+	for col := range n {
+		if !slices.Contains(doc.writeOrder, col) {
+			order = append(order, col)
+		}
+	}
+	return order
+}
+
 // Write, writes the currently line to a slice of bytes based on the current line in process, calling write will increment the counter after each successful call.
 // Once all lines are process will return will return empty slice, EOF
+//
+// When `EmitHeaders` is false the header line is skipped, returning ErrOmitHeaders along with a byte order mark if one is
+// written, and the next call writes the following line. [Document.WriteAll] and the other writers continue past it.
 func (doc *Document) Write() ([]byte, error) {
 	doc.startedWriting = true
 	buf := make([]byte, 0)
@@ -442,8 +860,19 @@ func (doc *Document) Write() ([]byte, error) {
 		return buf, io.EOF
 	}
 
+	if doc.currentWriteLine == 0 {
+		doc.calculateWriteOrder()
+		doc.calculateFormattedWidths()
+		doc.calculateCommentColumn()
+		doc.dataLinesWritten = 0
+	}
+
 	line := doc.lines[doc.currentWriteLine]
-	if doc.HasHeaders() && !doc.EmitHeaders && doc.currentWriteLine == doc.headerLine {
+	if doc.currentWriteLine == 0 && doc.EmitBOM {
+		buf = append(buf, 0xEF, 0xBB, 0xBF)
+	}
+	if !doc.EmitHeaders && line.IsHeader() {
+		doc.currentWriteLine += 1
 		return buf, ErrOmitHeaders
 	}
 	// if configured to be tabular, not an empty line, and has too little/many fields compared to headers return an error
@@ -451,16 +880,51 @@ func (doc *Document) Write() ([]byte, error) {
 		return buf, &WriteError{line: line.LineNumber(), headerCount: len(doc.Headers()), fieldIndex: line.FieldCount(), err: ErrFieldCount, expectedFieldCount: len(doc.Headers()), fieldValues: line.Fields()}
 	}
 
-	for i, field := range line.Fields() {
-		mw, err := doc.MaxColumnWidth(i)
+	if doc.ControlChars == ControlCharsReject {
+		if err := doc.checkControlChars(line); err != nil {
+			return buf, err
+		}
+	}
+	if len(doc.rawColumns) > 0 {
+		if err := doc.checkRawColumns(line); err != nil {
+			return buf, err
+		}
+	}
+
+	if line.FieldCount() > 0 && !line.IsHeader() {
+		if doc.GroupRows > 0 && doc.dataLinesWritten > 0 && doc.dataLinesWritten%doc.GroupRows == 0 {
+			buf = append(buf, doc.GroupSeparator+"\n"...)
+		}
+		doc.dataLinesWritten++
+	}
+
+	if preserved, ok := doc.appendPreserved(buf, line); ok {
+		doc.currentWriteLine += 1
+		return append(preserved, '\n'), nil
+	}
+
+	// the width of the current visual line, which is only tracked to wrap lines wider than MaxLineWidth
+	lw := 0
+	fields := line.Fields()
+	for pos, i := range doc.fieldOrder(len(fields)) {
+		field := fields[i]
+		mw, err := doc.writeWidth(i)
 		if err != nil {
-			continue
+			// the field index was never given a width, e.g. after the widths were edited, so recalculate from every line
+			doc.CalculateMaxFieldLengths()
+			mw, _ = doc.writeWidth(i)
 		}
-		v := field.SerializeText()
+		mw = max(mw, doc.minColumnWidth[i])
+		v := doc.cellText(line, i, &field)
 		p := utf8.RuneCountInString(v)
-		if doc.Tabular && (len(line.Fields())-1 != i) {
+		if doc.Tabular && (len(fields)-1 != pos) {
 			for {
 				// pad value with single spaces unless it's the last column or line has a comment
+				if p < mw && doc.alignment[i] == AlignRight {
+					v = fmt.Sprintf("%s%s", " ", v)
+					p = utf8.RuneCountInString(v)
+					continue
+				}
 				if p < mw {
 					v = fmt.Sprintf("%s%s", v, " ")
 					p = utf8.RuneCountInString(v)
@@ -470,17 +934,20 @@ func (doc *Document) Write() ([]byte, error) {
 			}
 		}
 
-		if i == 0 {
+		if pos == 0 {
 			buf = append(buf, []byte(v)...)
+			lw += p
 		} else {
-			buf = append(buf, internal.RuneToBytes(doc.padding)...)
-			buf = append(buf, []byte(v)...)
+			buf, lw = doc.appendWrapped(buf, lw, v)
 		}
 	}
 	if len(line.Comment()) > 0 {
 		if len(buf) > 0 {
-			buf = append(buf, internal.RuneToBytes(doc.padding)...)
-			buf = fmt.Appendf(buf, "#%s", line.Comment())
+			if doc.AlignComments && lw < doc.commentColumn {
+				buf = append(buf, strings.Repeat(" ", doc.commentColumn-lw)...)
+				lw = doc.commentColumn
+			}
+			buf, lw = doc.appendWrapped(buf, lw, "#"+line.Comment())
 		} else {
 			buf = fmt.Appendf(buf, "#%s", line.Comment())
 
@@ -491,6 +958,25 @@ func (doc *Document) Write() ([]byte, error) {
 	return buf, nil
 }
 
+// The marker at the start of a visual line continuing a line wrapped by `MaxLineWidth`
+const wrapIndent = "  > "
+
+// Appends the padding and v to the line, starting a new visual line with the wrap marker instead when
+// `doc.MaxLineWidth` is set and v would not fit. Returns the line and the width of the current visual line.
+func (doc *Document) appendWrapped(buf []byte, lw int, v string) ([]byte, int) {
+	w := len(doc.padding) + utf8.RuneCountInString(v)
+	if doc.MaxLineWidth > 0 && lw > 0 && lw+w > doc.MaxLineWidth {
+		buf = bytes.TrimRightFunc(buf, internal.IsFieldDelimiter)
+		buf = append(buf, '\n')
+		buf = append(buf, wrapIndent...)
+		buf = append(buf, v...)
+		return buf, utf8.RuneCountInString(wrapIndent) + utf8.RuneCountInString(v)
+	}
+	buf = append(buf, internal.RuneToBytes(doc.padding)...)
+	buf = append(buf, v...)
+	return buf, lw + w
+}
+
 func (doc *Document) WriteAll() ([]byte, error) {
 	data := make([]byte, 0)
 	doc.currentWriteLine = 0
@@ -500,7 +986,7 @@ func (doc *Document) WriteAll() ([]byte, error) {
 		if err == io.EOF {
 			break
 		}
-		if err != nil {
+		if err != nil && err != ErrOmitHeaders {
 			return data, err
 		}
 		data = append(data, d...)
@@ -508,6 +994,50 @@ func (doc *Document) WriteAll() ([]byte, error) {
 	return data, nil
 }
 
+// Runs [Line.Validate] on every line with fields, returning whether all of them are valid along with the error of each
+// invalid line, reporting its line number and its field count against the expected count, rather than stopping at the
+// first. Blank and comment-only lines are skipped the same as when writing, non-tabular documents are always valid.
+func (doc *Document) Validate() (bool, []error) {
+	errs := make([]error, 0)
+	for _, line := range doc.lines {
+		if line.FieldCount() == 0 {
+			continue
+		}
+		if ok, err := line.Validate(); !ok {
+			errs = append(errs, err)
+		}
+	}
+	return len(errs) == 0, errs
+}
+
+// Compares the headers, line count, and the values, null flags and comments of every line of the documents, returning
+// whether they are equal along with a description of the first difference. Formatting options such as padding and
+// column widths are not compared. Lines and fields are 1-indexed in the description.
+func (doc *Document) Equal(other *Document) (bool, string) {
+	if !slices.Equal(doc.headers, other.headers) {
+		return false, fmt.Sprintf("headers differ, %s and %s", strings.Join(internal.SerializeValues(doc.headers), " "), strings.Join(internal.SerializeValues(other.headers), " "))
+	}
+	if len(doc.lines) != len(other.lines) {
+		return false, fmt.Sprintf("line counts differ, %d and %d", len(doc.lines), len(other.lines))
+	}
+	for i, line := range doc.lines {
+		fields, otherFields := line.Fields(), other.lines[i].Fields()
+		if len(fields) != len(otherFields) {
+			return false, fmt.Sprintf("line %d: field counts differ, %d and %d", i+1, len(fields), len(otherFields))
+		}
+		for j, field := range fields {
+			otherField := otherFields[j]
+			if field.IsNull != otherField.IsNull || (!field.IsNull && field.Value != otherField.Value) {
+				return false, fmt.Sprintf("line %d, field %d: values differ, %s and %s", i+1, j+1, field.SerializeText(), otherField.SerializeText())
+			}
+		}
+		if line.Comment() != other.lines[i].Comment() {
+			return false, fmt.Sprintf("line %d: comments differ, %q and %q", i+1, line.Comment(), other.lines[i].Comment())
+		}
+	}
+	return true, ""
+}
+
 func (doc *Document) LineCount() int {
 	return len(doc.lines)
 }
@@ -554,6 +1084,81 @@ func (doc *Document) SetMaxColumnWidth(col int, len int) {
 	}
 }
 
+// The alignment of values within a padded column
+type Alignment int
+
+const (
+	AlignLeft Alignment = iota
+	AlignRight
+)
+
+// Sets the alignment of the 0-indexed column `col` when writing a tabular document, right aligned values are padded
+// with leading spaces instead of trailing spaces. The last column is never padded.
+func (doc *Document) SetColumnAlignment(col int, align Alignment) {
+	if doc.alignment == nil {
+		doc.alignment = make(map[int]Alignment)
+	}
+	doc.alignment[col] = align
+}
+
+// Sets the `fmt` format the numbers of the 0-indexed column `col` are written with, such as `%.2f` for fixed decimals or
+// `$%.2f` for currency, without changing the stored values. Values that are not numbers, nulls and the header are written
+// as is, integer verbs such as `%05d` only format integers. See [internal.FormatNumber]
+//
+// The column is padded to the width of its widest formatted value, combine it with [Document.SetColumnAlignment] and
+// [AlignRight] to line up the digits. A [CellFormatter] takes precedence over the format and an empty format removes it.
+// The format moves with its column when columns are deleted or reordered.
+//
+// Returns ErrInvalidNumberFormat if the format does not have exactly one directive with a number verb, one of
+// `d`, `b`, `o`, `x`, `X`, `e`, `E`, `f`, `F`, `g` or `G`.
+func (doc *Document) SetColumnFormat(col int, format string) error {
+	if format == "" {
+		delete(doc.columnFormats, col)
+		return nil
+	}
+	if !internal.IsNumberFormat(format) {
+		return fmt.Errorf("%w: %s", ErrInvalidNumberFormat, format)
+	}
+	if doc.columnFormats == nil {
+		doc.columnFormats = make(map[int]string)
+	}
+	doc.columnFormats[col] = format
+	return nil
+}
+
+// Writes the values of the 0-indexed column `col` verbatim, without quoting them or a [Document.SetColumnFormat] format,
+// for columns holding tokens that are already serialized as WSV such as `"a b"`. A value of `-` is written as is and read
+// back as null unless the column is read raw as well with the `RawColumns` option of the reader. Nulls and the header
+// are written as usual.
+//
+// The values must not contain delimiters outside of double quotes or a `#`, otherwise the line is not read back with
+// the same fields. An empty value would be written as nothing, writing it returns a *WriteError wrapping ErrEmptyRawValue,
+// write an empty string as `""` instead. The column stays raw as it moves when columns are deleted or reordered.
+func (doc *Document) SetRawColumn(col int) {
+	if doc.rawColumns == nil {
+		doc.rawColumns = make(map[int]bool)
+	}
+	doc.rawColumns[col] = true
+}
+
+// Sets the minimum width the 0-indexed column `col` is padded to when writing a tabular document,
+// columns with wider values are still padded to the widest value.
+//
+// Returns ErrFieldIndexedNotFound for a negative column and ErrInvalidColumnWidth for a negative width.
+func (doc *Document) SetMinColumnWidth(col, width int) error {
+	if col < 0 {
+		return ErrFieldIndexedNotFound
+	}
+	if width < 0 {
+		return ErrInvalidColumnWidth
+	}
+	if doc.minColumnWidth == nil {
+		doc.minColumnWidth = make(map[int]int)
+	}
+	doc.minColumnWidth[col] = width
+	return nil
+}
+
 func (doc *Document) MaxColumnWidth(col int) (int, error) {
 	v, ok := doc.maxColumnWidth[col]
 	if !ok {
@@ -573,26 +1178,671 @@ func (doc *Document) Headers() []string {
 	return doc.headers
 }
 
+// Renames the header of the 0-indexed column `fi` to `val`, updating the header line's value and the field name
+// of the field at that column on every data line.
+//
+// Returns ErrFieldIndexedNotFound if the header line does not have the column.
 func (doc *Document) UpdateHeader(fi int, val string) error {
 	if !doc.HasHeaders() {
 		return nil
 	}
+	if fi < 0 || len(doc.headers)-1 < fi {
+		return ErrFieldIndexedNotFound
+	}
+	headerLine, err := doc.Line(doc.headerLine)
+	if err != nil {
+		return err
+	}
+	if err := headerLine.UpdateField(fi, val); err != nil {
+		return err
+	}
+	for _, line := range doc.lines {
+		if line.FieldCount() <= fi {
+			continue
+		}
+		if err := line.UpdateFieldName(fi, val); err != nil {
+			return err
+		}
+	}
+	doc.headers[fi] = val
+	return nil
+}
+
+// Renames the first column with the header `old` to `new`. See [Document.UpdateHeader]
+//
+// Returns ErrFieldNameNotFound if there is no column with the header `old`.
+func (doc *Document) RenameColumn(old, new string) error {
+	col := slices.Index(doc.headers, old)
+	if col < 0 {
+		return ErrFieldNameNotFound
+	}
+	return doc.UpdateHeader(col, new)
+}
+
+// Adds a column to the end of every line, `header` is appended to the header line and `values` is called for each data line
+// to compute the value appended to it. The value is appended as is, a `-` is a literal value not a null.
+//
+// Lines without any fields, blank or comment only lines, are left untouched.
+//
+// Returns ErrNonTabularDocument for non-tabular documents, ErrLineNotFound when the document does not have a header line
+// and a *WriteError if the document started to write.
+func (doc *Document) AppendColumn(header string, values func(line Line) string) error {
+	if doc.startedWriting {
+		return &WriteError{err: ErrStartedToWrite, line: doc.currentWriteLine}
+	}
+	if !doc.Tabular {
+		return ErrNonTabularDocument
+	}
+	headerLine, err := doc.Line(doc.headerLine)
+	if err != nil {
+		return err
+	}
+	if err := headerLine.Append(header); err != nil {
+		return err
+	}
+	for _, line := range doc.lines {
+		if line.IsHeader() || line.FieldCount() == 0 {
+			continue
+		}
+		if err := line.Append(values(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes the first column with the header `name` from every line. See [Document.DeleteColumnAt]
+//
+// Returns ErrFieldNameNotFound if there is no column with the header `name`.
+func (doc *Document) DeleteColumn(name string) error {
+	if doc.startedWriting {
+		return &WriteError{err: ErrStartedToWrite, line: doc.currentWriteLine}
+	}
+	col := slices.Index(doc.headers, name)
+	if col < 0 {
+		return ErrFieldNameNotFound
+	}
+	return doc.DeleteColumnAt(col)
+}
 
-	for i := range doc.LineCount() {
-		line, _ := doc.Line(i)
-		for fi := range line.FieldCount() {
-			if fi == 0 {
-				line.UpdateField(fi, val)
+// Removes the field at the 0-indexed column `col` from every line that has it, including the header line,
+// re-indexing the fields that follow and recalculating the column widths. The settings of the following columns,
+// such as alignments and number formats, move with them.
+//
+// Returns ErrFieldIndexedNotFound if no line has the column and a *WriteError if the document started to write.
+func (doc *Document) DeleteColumnAt(col int) error {
+	if doc.startedWriting {
+		return &WriteError{err: ErrStartedToWrite, line: doc.currentWriteLine}
+	}
+	found := false
+	for _, line := range doc.lines {
+		if dl, ok := line.(*documentLine); ok && dl.removeField(col) {
+			found = true
+		}
+	}
+	if !found {
+		return ErrFieldIndexedNotFound
+	}
+	if col < len(doc.headers) {
+		doc.headers = slices.Delete(doc.headers, col, col+1)
+	}
+	doc.remapColumnSettings(func(c int) (int, bool) {
+		if c > col {
+			return c - 1, true
+		}
+		return c, c != col
+	})
+	doc.maxColumnWidth = make(map[int]int)
+	doc.CalculateMaxFieldLengths()
+	return nil
+}
+
+// Keeps only the columns with the headers `names`, in the order of `names`, removing every other column from every line.
+// Column alignments, minimum widths, number formats and raw columns move with their column and the column widths are recalculated.
+//
+// Returns ErrFieldNameNotFound if there is no column for one of the `names` and a *WriteError if the document started to write.
+func (doc *Document) SelectColumns(names ...string) error {
+	if doc.startedWriting {
+		return &WriteError{err: ErrStartedToWrite, line: doc.currentWriteLine}
+	}
+	cols := make([]int, len(names))
+	for i, name := range names {
+		cols[i] = slices.Index(doc.headers, name)
+		if cols[i] < 0 {
+			return fmt.Errorf("%w: %s", ErrFieldNameNotFound, name)
+		}
+	}
+	doc.reorderColumns(cols)
+	return nil
+}
+
+// Reverses the order of the columns of every line, including the header line, so the last column becomes the first.
+// Column alignments, minimum widths, number formats and raw columns move with their column and the column widths are recalculated.
+// Lines with fewer fields than the widest line are padded with nulls.
+//
+// Returns a *WriteError if the document started to write.
+func (doc *Document) ReverseColumns() error {
+	if doc.startedWriting {
+		return &WriteError{err: ErrStartedToWrite, line: doc.currentWriteLine}
+	}
+	width := len(doc.headers)
+	for _, line := range doc.lines {
+		width = max(width, line.FieldCount())
+	}
+	cols := make([]int, width)
+	for i := range cols {
+		cols[i] = width - 1 - i
+	}
+	doc.reorderColumns(cols)
+	return nil
+}
+
+// Rearranges the fields of every line so the column at cols[i] becomes column i, dropping the columns not in cols
+func (doc *Document) reorderColumns(cols []int) {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		if col < len(doc.headers) {
+			names[i] = doc.headers[col]
+		}
+	}
+	for _, line := range doc.lines {
+		if dl, ok := line.(*documentLine); ok {
+			dl.selectFields(cols, names)
+		}
+	}
+	doc.remapColumnSettings(func(c int) (int, bool) {
+		i := slices.Index(cols, c)
+		return i, i >= 0
+	})
+	if len(doc.headers) > 0 {
+		doc.headers = names
+	}
+	doc.maxColumnWidth = make(map[int]int)
+	doc.CalculateMaxFieldLengths()
+}
+
+// Moves the minimum widths, alignments, number formats and raw columns set for each column to the column `to` returns,
+// dropping the ones of columns `to` returns false for
+func (doc *Document) remapColumnSettings(to func(col int) (int, bool)) {
+	doc.minColumnWidth = remapColumns(doc.minColumnWidth, to)
+	doc.alignment = remapColumns(doc.alignment, to)
+	doc.columnFormats = remapColumns(doc.columnFormats, to)
+	doc.rawColumns = remapColumns(doc.rawColumns, to)
+}
+
+func remapColumns[V any](m map[int]V, to func(col int) (int, bool)) map[int]V {
+	if m == nil {
+		return nil
+	}
+	remapped := make(map[int]V, len(m))
+	for col, v := range m {
+		if c, ok := to(col); ok {
+			remapped[c] = v
+		}
+	}
+	return remapped
+}
+
+// Returns a new document with the rows and columns swapped, the field at line i and column j moves to line j and column i,
+// so the headers become the first column. Ragged lines are padded with nulls and lines without fields are left out.
+//
+// Comments cannot be transposed and are dropped. Column alignments, minimum widths, number formats and raw columns are not carried over.
+//
+// Returns ErrNonTabularDocument for non-tabular documents.
+func (doc *Document) Transpose() (*Document, error) {
+	if !doc.Tabular {
+		return nil, ErrNonTabularDocument
+	}
+	rows := make([]Line, 0, len(doc.lines))
+	width := 0
+	for _, line := range doc.lines {
+		if line.FieldCount() == 0 {
+			continue
+		}
+		rows = append(rows, line)
+		width = max(width, line.FieldCount())
+	}
+	nd := doc.emptyCopy()
+	nd.minColumnWidth = nil
+	nd.alignment = nil
+	nd.columnFormats = nil
+	nd.rawColumns = nil
+	for col := range width {
+		nl, err := nd.AddLine()
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			field, ferr := row.Field(col)
+			if ferr != nil || field.IsNull {
+				err = nl.AppendNull()
+			} else {
+				err = nl.Append(field.Value)
 			}
-			err := line.UpdateFieldName(fi, val)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nd, nil
+}
+
+// Returns a new document with the header line and the lines `keep` returns true for, in the same order.
+// The new document has the same write options as doc.
+func (doc *Document) Filter(keep func(line Line) bool) (*Document, error) {
+	nd := doc.emptyCopy()
+	for _, line := range doc.lines {
+		if !line.IsHeader() && !keep(line) {
+			continue
+		}
+		if err := nd.appendLineCopy(line); err != nil {
+			return nil, err
+		}
+	}
+	return nd, nil
+}
+
+// Same as [Document.Filter] selecting lines by their comment, `keep` is called with an empty string for lines without a comment
+func (doc *Document) FilterByComment(keep func(comment string) bool) (*Document, error) {
+	return doc.Filter(func(line Line) bool {
+		return keep(line.Comment())
+	})
+}
+
+// Returns the number of data lines `pred` returns true for, without copying them like [Document.Filter].
+// The header line, blank lines and comment-only lines are not counted or passed to `pred`.
+func (doc *Document) CountWhere(pred func(line Line) bool) int {
+	count := 0
+	for _, line := range doc.lines {
+		if line.IsHeader() || line.FieldCount() == 0 {
+			continue
+		}
+		if pred(line) {
+			count++
+		}
+	}
+	return count
+}
+
+// Returns a deep copy of the document, the lines, fields, headers, column widths and options are copied so changes
+// to the clone, such as sorting or deleting lines, do not affect doc. The clone has not started writing.
+func (doc *Document) Clone() *Document {
+	nd := doc.emptyCopy()
+	nd.PreserveFormatting = doc.PreserveFormatting
+	nd.headers = slices.Clone(doc.headers)
+	nd.headerLine = doc.headerLine
+	nd.maxColumnWidth = maps.Clone(doc.maxColumnWidth)
+	nd.lines = make([]Line, 0, len(doc.lines))
+	// the copy of each line, to carry over the state kept by line
+	copies := make(map[Line]Line, len(doc.lines))
+	for _, line := range doc.lines {
+		dl, ok := line.(*documentLine)
+		if !ok {
+			continue
+		}
+		nl := *dl
+		nl.doc = nd
+		nl.fields = slices.Clone(dl.fields)
+		nd.lines = append(nd.lines, &nl)
+		copies[line] = &nl
+	}
+	for line := range doc.pinned {
+		if nl, ok := copies[line]; ok {
+			if nd.pinned == nil {
+				nd.pinned = make(map[Line]bool)
+			}
+			nd.pinned[nl] = true
+		}
+	}
+	for line, p := range doc.formats {
+		if nl, ok := copies[line]; ok {
+			if nd.formats == nil {
+				nd.formats = make(map[Line]preservedLine)
+			}
+			nd.formats[nl] = p
+		}
+	}
+	return nd
+}
+
+// a new document without any lines with the same write options as doc
+func (doc *Document) emptyCopy() *Document {
+	nd := NewDocument()
+	nd.Tabular = doc.Tabular
+	nd.EmitHeaders = doc.EmitHeaders
+	nd.BackslashEscapedQuotes = doc.BackslashEscapedQuotes
+	nd.EmitBOM = doc.EmitBOM
+	nd.MaxLineWidth = doc.MaxLineWidth
+	nd.IsDelimiter = doc.IsDelimiter
+	nd.NullString = doc.NullString
+	nd.ControlChars = doc.ControlChars
+	nd.columnOrder = slices.Clone(doc.columnOrder)
+	nd.columnFormats = maps.Clone(doc.columnFormats)
+	nd.rawColumns = maps.Clone(doc.rawColumns)
+	nd.AlignComments = doc.AlignComments
+	nd.GroupRows = doc.GroupRows
+	nd.GroupSeparator = doc.GroupSeparator
+	nd.padding = slices.Clone(doc.padding)
+	nd.hasHeaders = doc.hasHeaders
+	nd.cellFormatter = doc.cellFormatter
+	nd.quoting = doc.quoting
+	nd.minColumnWidth = maps.Clone(doc.minColumnWidth)
+	nd.alignment = maps.Clone(doc.alignment)
+	return nd
+}
+
+// adds a line to the end of the document with the fields and comment of line
+func (doc *Document) appendLineCopy(line Line) error {
+	nl, err := doc.AddLine()
+	if err != nil {
+		return err
+	}
+	for _, field := range line.Fields() {
+		if field.IsNull {
+			err = nl.AppendNull()
+		} else {
+			err = nl.Append(field.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	nl.UpdateComment(line.Comment())
+	return nil
+}
+
+// Removes the columns where the value of every data line is null or empty, including the header of the column.
+// Only the columns with the headers in `names` are considered, or every column when no names are provided.
+//
+// Returns a *WriteError if the document started to write.
+func (doc *Document) DeleteEmptyColumns(names ...string) error {
+	if doc.startedWriting {
+		return &WriteError{err: ErrStartedToWrite, line: doc.currentWriteLine}
+	}
+	// delete from the last column so the earlier column indices stay valid
+	for col := len(doc.headers) - 1; col >= 0; col-- {
+		if len(names) > 0 && !slices.Contains(names, doc.headers[col]) {
+			continue
+		}
+		if !doc.columnIsEmpty(col) {
+			continue
+		}
+		if err := doc.DeleteColumnAt(col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returns true if the value of every data line in the column is null or empty
+func (doc *Document) columnIsEmpty(col int) bool {
+	for _, line := range doc.lines {
+		if line.IsHeader() {
+			continue
+		}
+		field, err := line.Field(col)
+		if err != nil {
+			continue
+		}
+		if !field.IsNull && field.Value != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns a Go `[]string{...}` literal of the values of the first column with the header `name`, such as to embed
+// a small lookup table in code. Values are quoted with [strconv.Quote], null values are written as an empty string
+// and lines without the column are skipped.
+//
+// Returns ErrFieldNameNotFound if there is no column with the header `name`.
+func (doc *Document) ColumnGoLiteral(name string) (string, error) {
+	col := slices.Index(doc.headers, name)
+	if col < 0 {
+		return "", ErrFieldNameNotFound
+	}
+	values := make([]string, 0, len(doc.lines))
+	for _, line := range doc.lines {
+		if line.IsHeader() {
+			continue
+		}
+		field, err := line.Field(col)
+		if err != nil {
+			continue
+		}
+		values = append(values, strconv.Quote(field.Value))
+	}
+	return "[]string{" + strings.Join(values, ", ") + "}", nil
+}
+
+// Removes the leading and trailing white space of every non-null value in the first column with the header `name`,
+// recalculating the column widths.
+//
+// Returns ErrFieldNameNotFound if there is no column with the header `name` and a *WriteError if the document started to write.
+func (doc *Document) TrimColumn(name string) error {
+	if doc.startedWriting {
+		return &WriteError{err: ErrStartedToWrite, line: doc.currentWriteLine}
+	}
+	col := slices.Index(doc.headers, name)
+	if col < 0 {
+		return ErrFieldNameNotFound
+	}
+	for _, line := range doc.lines {
+		if line.IsHeader() {
+			continue
+		}
+		field, err := line.Field(col)
+		if err != nil || field.IsNull {
+			continue
+		}
+		if err := line.UpdateField(col, strings.TrimSpace(field.Value)); err != nil {
+			return err
+		}
+	}
+	doc.maxColumnWidth = make(map[int]int)
+	doc.CalculateMaxFieldLengths()
+	return nil
+}
+
+// Converts every empty string value of the data lines into a null, recalculating the column widths.
+//
+// Returns a *WriteError if the document started to write.
+func (doc *Document) NullifyEmpties() error {
+	return doc.normalizeNulls(func(field *internal.Field) {
+		if !field.IsNull && field.Value == "" {
+			field.IsNull = true
+		}
+	})
+}
+
+// Converts every null value of the data lines into an empty string, recalculating the column widths.
+//
+// Returns a *WriteError if the document started to write.
+func (doc *Document) EmptyNulls() error {
+	return doc.normalizeNulls(func(field *internal.Field) {
+		if field.IsNull {
+			field.IsNull = false
+			field.Value = ""
+		}
+	})
+}
+
+func (doc *Document) normalizeNulls(convert func(field *internal.Field)) error {
+	if doc.startedWriting {
+		return &WriteError{err: ErrStartedToWrite, line: doc.currentWriteLine}
+	}
+	for _, line := range doc.lines {
+		if line.IsHeader() {
+			continue
+		}
+		for i := range line.FieldCount() {
+			field, err := line.Field(i)
 			if err != nil {
 				return err
 			}
+			convert(field)
 		}
 	}
+	doc.maxColumnWidth = make(map[int]int)
+	doc.CalculateMaxFieldLengths()
 	return nil
 }
 
+// Returns true if the value of any non-null data field in the column with the header `name` equals `value`.
+//
+// Returns ErrFieldNameNotFound if there is no column with the header `name`.
+func (doc *Document) ColumnContains(name, value string) (bool, error) {
+	if !slices.Contains(doc.headers, name) {
+		return false, ErrFieldNameNotFound
+	}
+	for _, line := range doc.lines {
+		if line.IsHeader() {
+			continue
+		}
+		field, err := line.FieldByName(name)
+		if err != nil || field.IsNull {
+			continue
+		}
+		if field.Value == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Returns the distinct non-null values of the data lines in the column with the header `name`, in the order they are first seen.
+//
+// Returns ErrFieldNameNotFound if there is no column with the header `name`.
+func (doc *Document) ColumnUnique(name string) ([]string, error) {
+	if !slices.Contains(doc.headers, name) {
+		return nil, ErrFieldNameNotFound
+	}
+	seen := make(map[string]bool)
+	values := make([]string, 0)
+	for _, line := range doc.lines {
+		if line.IsHeader() {
+			continue
+		}
+		field, err := line.FieldByName(name)
+		if err != nil || field.IsNull || seen[field.Value] {
+			continue
+		}
+		seen[field.Value] = true
+		values = append(values, field.Value)
+	}
+	return values, nil
+}
+
+// An option to alter the behavior of [Document.MapColumns]
+type MapColumnsOption func(opts *mapColumnsOptions)
+
+type mapColumnsOptions struct {
+	// a repeated key takes the value of its last line instead of returning an error
+	lastWins bool
+}
+
+// A key repeated on more than one line takes the value of its last line instead of returning ErrDuplicateKey
+func MapLastWins() MapColumnsOption {
+	return func(opts *mapColumnsOptions) {
+		opts.lastWins = true
+	}
+}
+
+// Returns a map of the values in the column `keyName` to the value of the column `valName` on the same line, such as to
+// build a lookup from a code to a name. Lines with a null key are skipped and a null value is mapped to an empty string.
+//
+// Returns ErrFieldNameNotFound if either column does not exist and an error wrapping ErrDuplicateKey if a key is
+// repeated, unless [MapLastWins] is provided.
+func (doc *Document) MapColumns(keyName, valName string, options ...MapColumnsOption) (map[string]string, error) {
+	opts := mapColumnsOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+	if !slices.Contains(doc.headers, keyName) || !slices.Contains(doc.headers, valName) {
+		return nil, ErrFieldNameNotFound
+	}
+	m := make(map[string]string)
+	for _, line := range doc.lines {
+		if line.IsHeader() {
+			continue
+		}
+		key, err := line.FieldByName(keyName)
+		if err != nil || key.IsNull {
+			continue
+		}
+		val, err := line.FieldByName(valName)
+		if err != nil {
+			continue
+		}
+		if _, ok := m[key.Value]; ok && !opts.lastWins {
+			return nil, fmt.Errorf("%w %s on line %d", ErrDuplicateKey, internal.SerializeValue(key.Value), line.LineNumber())
+		}
+		m[key.Value] = val.Value
+	}
+	return m, nil
+}
+
+// Profile of the values of a column returned by [Document.ColumnStats]
+type ColumnStats struct {
+	// number of non-null values
+	Count int
+	// number of null values
+	NullCount int
+	// true when every non-null value parses as a number, Min, Max, Sum and Mean are only set when true
+	Numeric bool
+	Min     float64
+	Max     float64
+	Sum     float64
+	Mean    float64
+}
+
+// Returns the count, null count, and when every non-null value parses as a number the min, max, sum and mean of the
+// values in the column with the header `name`. The header line and lines without the column are skipped.
+//
+// Returns ErrFieldNameNotFound if there is no column with the header `name`.
+func (doc *Document) ColumnStats(name string) (ColumnStats, error) {
+	stats := ColumnStats{Numeric: true}
+	if !slices.Contains(doc.headers, name) {
+		return stats, ErrFieldNameNotFound
+	}
+	for _, line := range doc.lines {
+		if line.IsHeader() {
+			continue
+		}
+		field, err := line.FieldByName(name)
+		if err != nil {
+			continue
+		}
+		if field.IsNull {
+			stats.NullCount++
+			continue
+		}
+		stats.Count++
+		if !stats.Numeric {
+			continue
+		}
+		// NaN, Inf and hexadecimal floats parse but are not numbers of the column
+		v, err := strconv.ParseFloat(field.Value, 64)
+		if err != nil || !internal.IsDecimal(field.Value) {
+			stats.Numeric = false
+			continue
+		}
+		if stats.Count == 1 || v < stats.Min {
+			stats.Min = v
+		}
+		if stats.Count == 1 || v > stats.Max {
+			stats.Max = v
+		}
+		stats.Sum += v
+	}
+	if !stats.Numeric || stats.Count == 0 {
+		stats.Min, stats.Max, stats.Sum = 0, 0, 0
+		return stats, nil
+	}
+	stats.Mean = stats.Sum / float64(stats.Count)
+	return stats, nil
+}
+
 func (doc *Document) AppendHeader(val string) {
 	doc.headers = append(doc.headers, val)
 }
@@ -618,7 +1868,7 @@ func (doc *Document) WriteAllTo(w io.Writer) error {
 			break
 		}
 
-		if err != nil {
+		if err != nil && err != ErrOmitHeaders {
 			return err
 		}
 		_, err = w.Write(d)
@@ -629,6 +1879,56 @@ func (doc *Document) WriteAllTo(w io.Writer) error {
 	return nil
 }
 
+// Writes the whole document to w, implementing [io.WriterTo]. Returns the number of bytes written and the first error
+// from writing a line or from w, a write to w that is short without an error returns [io.ErrShortWrite].
+func (doc *Document) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	doc.currentWriteLine = 0
+	for {
+		d, err := doc.Write()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != ErrOmitHeaders {
+			return total, err
+		}
+		n, err := w.Write(d)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		if n < len(d) {
+			return total, io.ErrShortWrite
+		}
+	}
+	return total, nil
+}
+
+// Writes each data line of doc to w as a record, one `header: value` line per field followed by a blank line,
+// useful to inspect documents with many columns. Values are serialized the same as [Document.Write], nulls as `-`.
+//
+// Fields without a header use their 0-indexed column as the name. Lines without any fields are skipped.
+func WriteVertical(doc *Document, w io.Writer) error {
+	for _, line := range doc.lines {
+		if line.IsHeader() || line.FieldCount() == 0 {
+			continue
+		}
+		buf := make([]byte, 0)
+		for i, field := range line.Fields() {
+			name := strconv.Itoa(i)
+			if i < len(doc.headers) {
+				name = doc.headers[i]
+			}
+			buf = fmt.Appendf(buf, "%s: %s\n", name, doc.cellText(line, i, &field))
+		}
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func NewDocument() *Document {
 	doc := Document{
 		Tabular:          true,
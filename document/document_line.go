@@ -3,6 +3,7 @@ package document
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/campfhir/wsv/internal"
 )
@@ -108,7 +109,11 @@ func (line *documentLine) Append(val string) error {
 	}
 	if line.doc.HasHeaders() && (line.doc.headerLine == 0 || line.line == line.doc.headerLine) {
 		field.IsHeader = true
+		// empty headers are named by their position
 		field.FieldName = val
+		if val == "" {
+			field.FieldName = internal.PositionalColumnName(len(line.fields))
+		}
 		line.doc.headerLine = line.line
 	}
 	fieldInd := len(line.fields)
@@ -125,7 +130,7 @@ func (line *documentLine) Append(val string) error {
 	fw := field.CalculateFieldLength()
 	line.doc.SetMaxColumnWidth(fieldInd, fw)
 	if line.doc.HasHeaders() && line.line == line.doc.headerLine {
-		line.doc.AppendHeader(val)
+		line.doc.AppendHeader(field.FieldName)
 	}
 	// increment the field count for the line
 	line.fieldCount++
@@ -136,7 +141,7 @@ func (line *documentLine) AppendNull() error {
 	field := internal.Field{IsNull: true}
 	if line.doc.HasHeaders() && (line.doc.headerLine == 0 || line.line == line.doc.headerLine) {
 		field.IsHeader = true
-		field.FieldName = "-"
+		field.FieldName = internal.PositionalColumnName(len(line.fields))
 		line.doc.headerLine = line.line
 	}
 	fieldInd := len(line.fields)
@@ -145,7 +150,7 @@ func (line *documentLine) AppendNull() error {
 		return err
 	}
 
-	if line.line > line.doc.headerLine && len(line.doc.Headers())-1 > fieldInd {
+	if line.line > line.doc.headerLine && len(line.doc.Headers())-1 >= fieldInd {
 		field.FieldName = line.doc.Headers()[fieldInd]
 	}
 	field.FieldIndex = fieldInd
@@ -153,7 +158,7 @@ func (line *documentLine) AppendNull() error {
 	fw := field.CalculateFieldLength()
 	line.doc.SetMaxColumnWidth(fieldInd, fw)
 	if line.doc.HasHeaders() && line.line == line.doc.headerLine {
-		line.doc.AppendHeader("-")
+		line.doc.AppendHeader(field.FieldName)
 	}
 	// increment the field count for the line
 	line.fieldCount++
@@ -226,6 +231,42 @@ func (line *documentLine) checkFieldIndex(fieldInd int) error {
 	return nil
 }
 
+// removes the field at the index, re-indexing the fields after it, returns false if there is no field at the index
+func (line *documentLine) removeField(fi int) bool {
+	if fi < 0 || len(line.fields)-1 < fi {
+		return false
+	}
+	line.fields = slices.Delete(line.fields, fi, fi+1)
+	for i := fi; i < len(line.fields); i++ {
+		line.fields[i].FieldIndex = i
+	}
+	line.fieldCount--
+	if line.currentField > fi {
+		line.currentField--
+	}
+	return true
+}
+
+// replaces the fields with the fields at the column indices `cols` in that order, re-indexing them,
+// columns the line does not have become null fields named by `names`
+func (line *documentLine) selectFields(cols []int, names []string) {
+	if len(line.fields) == 0 {
+		return
+	}
+	fields := make([]internal.Field, 0, len(cols))
+	for i, col := range cols {
+		field := internal.Field{IsNull: true, FieldName: names[i], IsHeader: line.IsHeader(), RowIndex: line.line}
+		if col < len(line.fields) {
+			field = line.fields[col]
+		}
+		field.FieldIndex = i
+		fields = append(fields, field)
+	}
+	line.fields = fields
+	line.fieldCount = len(fields)
+	line.currentField = 0
+}
+
 func (line *documentLine) UpdateFieldName(fi int, val string) error {
 	if len(line.fields)-1 < fi {
 		return ErrFieldCount
@@ -20,3 +20,19 @@ func TestDocumentLineValidateWithTabularData(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestDocumentLineAppendNullInLastColumn(t *testing.T) {
+	doc := document.NewDocument()
+	doc.AppendValues("Name", "Age")
+	line, _ := doc.AddLine()
+	line.Append("Scott")
+	line.AppendNull()
+
+	age, err := line.FieldByName("Age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !age.IsNull || age.FieldName != "Age" {
+		t.Errorf("expected the null field to be named Age but got %+v", age)
+	}
+}
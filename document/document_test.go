@@ -1,10 +1,15 @@
 package document
 
 import (
+	"errors"
 	"io"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/campfhir/wsv/internal"
 )
 
 func TestCreateTabularDocument(t *testing.T) {
@@ -246,8 +251,8 @@ func TestWriteLineWithHeader(t *testing.T) {
 		return
 	}
 
-	exp := `name   "hire date"   salary` + "\n" +
-		`scott  "2006-01-02"  $200,000,000`
+	exp := `name   "hire date"  salary` + "\n" +
+		`scott  2006-01-02   $200,000,000`
 
 	if exp != string(b) {
 		t.Errorf("expected [%s] but got [%s]", strings.ReplaceAll(exp, "\n", `\n`), strings.ReplaceAll(string(b), "\n", `\n`))
@@ -276,7 +281,7 @@ func TestWriteLineWithoutHeader(t *testing.T) {
 		return
 	}
 
-	exp := `scott  "2006-01-02"  $200,000,000`
+	exp := `scott  2006-01-02  $200,000,000`
 
 	if exp != string(b) {
 		t.Errorf("expected [%s] but got [%s]", strings.ReplaceAll(exp, "\n", `\n`), strings.ReplaceAll(string(b), "\n", `\n`))
@@ -305,7 +310,7 @@ func TestWriteLineWithoutHeaderWhereHeaderValuesWouldPadValue(t *testing.T) {
 		return
 	}
 
-	exp := `scott  "2006-01-02"  $200,000,000`
+	exp := `scott  2006-01-02  $200,000,000`
 
 	if exp != string(b) {
 		t.Errorf("expected [%s] but got [%s]", strings.ReplaceAll(exp, "\n", `\n`), strings.ReplaceAll(string(b), "\n", `\n`))
@@ -335,7 +340,7 @@ func TestWriteLineWithHeaderWhereHeaderValuesWouldPadValue(t *testing.T) {
 	}
 
 	exp := `"name of the person"  "hire date in the system"  "salary of the employee hired recently"` + "\n" +
-		`scott                 "2006-01-02"               $200,000,000`
+		`scott                 2006-01-02                 $200,000,000`
 
 	if exp != string(b) {
 		t.Errorf("expected [%s] but got [%s]", strings.ReplaceAll(exp, "\n", `\n`), strings.ReplaceAll(string(b), "\n", `\n`))
@@ -523,3 +528,1239 @@ func TestDurationFieldsAscSorting(t *testing.T) {
 		t.Error("did not sort the expected way", "\n", string(e), "\n", string(d))
 	}
 }
+
+func TestCellFormatterMasksOutputOnly(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "SSN", "Age")...)
+	doc.AppendLine(Fields("Scott", "123-45-6789", "33")...)
+	doc.AppendLine(Fields("Jane", "987-65-4321", "79")...)
+
+	doc.SetCellFormatter(func(line int, col int, field *internal.Field) string {
+		if col == 1 && !field.IsHeader {
+			return "***"
+		}
+		return field.SerializeText()
+	})
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   SSN  Age\n" +
+		"Scott  ***  33\n" +
+		"Jane   ***  79\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+
+	line, _ := doc.Line(2)
+	field, err := line.FieldByName("SSN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.Value != "123-45-6789" {
+		t.Error("expected the underlying value to be unchanged but got", field.Value)
+	}
+}
+
+func TestWriteColumnWithoutMaxWidth(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Age", "Gender")...)
+	doc.AppendLine(Fields("Scott", "18", "Male")...)
+	doc.AppendLine(Fields("Patrick", "100", "Female")...)
+	// simulate a column that never had its width recorded
+	delete(doc.maxColumnWidth, 1)
+
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name     Age  Gender\n" +
+		"Scott    18   Male\n" +
+		"Patrick  100  Female\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+	if w, err := doc.MaxColumnWidth(1); err != nil || w != 3 {
+		t.Error("expected the width of the column to be 3 but got", w, err)
+	}
+}
+
+func TestAppendColumn(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Age")...)
+	doc.AppendLine(Fields("Scott", "33")...)
+	doc.AddLine()
+	doc.AppendLine(Fields("Jane", "79")...)
+
+	err := doc.AppendColumn("Age Next Year", func(line Line) string {
+		age, _ := line.FieldByName("Age")
+		n, _ := strconv.Atoi(age.Value)
+		return strconv.Itoa(n + 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Headers()) != 3 || doc.Headers()[2] != "Age Next Year" {
+		t.Error("expected the new header to be added but got", doc.Headers())
+	}
+	line, _ := doc.Line(4)
+	if line.FieldCount() != 3 {
+		t.Error("expected the line to have 3 fields but got", line.FieldCount())
+	}
+	field, err := line.FieldByName("Age Next Year")
+	if err != nil || field.Value != "80" || field.FieldIndex != 2 {
+		t.Errorf("expected Age Next Year to be 80 but got %+v %s", field, err)
+	}
+	blank, _ := doc.Line(3)
+	if blank.FieldCount() != 0 {
+		t.Error("expected the blank line to be untouched but has", blank.FieldCount(), "fields")
+	}
+
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Age  \"Age Next Year\"\n" +
+		"Scott  33   34\n" +
+		"\n" +
+		"Jane   79   80\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+
+	err = doc.AppendColumn("Other", func(line Line) string { return "" })
+	if _, ok := err.(*WriteError); !ok {
+		t.Error("expected a write error after writing started but got", err)
+	}
+
+	doc = NewDocument()
+	doc.Tabular = false
+	doc.AppendLine(Fields("Name", "Age")...)
+	if err := doc.AppendColumn("Other", func(line Line) string { return "" }); err != ErrNonTabularDocument {
+		t.Error("expected ErrNonTabularDocument but got", err)
+	}
+}
+
+func TestDeleteColumn(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Notes", "Age", "Notes")...)
+	doc.AppendLine(Fields("Scott", "a very long note", "33", "second")...)
+	doc.AddLine()
+	doc.AppendLine(Fields("Jane", "short", "79", "other")...)
+
+	if err := doc.DeleteColumn("Missing"); err != ErrFieldNameNotFound {
+		t.Error("expected ErrFieldNameNotFound but got", err)
+	}
+	if err := doc.DeleteColumn("Notes"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(doc.Headers(), ",") != "Name,Age,Notes" {
+		t.Error("expected the first Notes column to be removed but got", doc.Headers())
+	}
+	line, _ := doc.Line(4)
+	if line.FieldCount() != 3 {
+		t.Error("expected 3 fields but got", line.FieldCount())
+	}
+	age, err := line.Field(1)
+	if err != nil || age.Value != "79" || age.FieldIndex != 1 || age.FieldName != "Age" {
+		t.Errorf("expected Age to be re-indexed to 1 but got %+v", age)
+	}
+	if w, _ := doc.MaxColumnWidth(1); w != 3 {
+		t.Error("expected the width of column 1 to be recalculated to 3 but got", w)
+	}
+
+	if err := doc.DeleteColumnAt(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.DeleteColumnAt(5); err != ErrFieldIndexedNotFound {
+		t.Error("expected ErrFieldIndexedNotFound but got", err)
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Age\n" +
+		"Scott  33\n" +
+		"\n" +
+		"Jane   79\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+	if _, ok := doc.DeleteColumn("Age").(*WriteError); !ok {
+		t.Error("expected a write error after writing started")
+	}
+}
+
+func TestSortingWithPinnedRows(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Points")...)
+	doc.AppendLine(Fields("Total", "276")...)
+	doc.AppendLine(Fields("Scott", "18")...)
+	doc.AppendLine(Fields("Patrick", "100")...)
+	doc.AppendLine(Fields("Jane", "79")...)
+	doc.AppendLine(Fields("Zak", "79")...)
+
+	if err := doc.PinRows(7); err != ErrLineNotFound {
+		t.Error("expected ErrLineNotFound but got", err)
+	}
+	if err := doc.PinRows(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.SortBy(SortNumber("Points"), Sort("Name")); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Name", "Total", "Jane", "Patrick", "Scott", "Zak"}
+	for i, line := range doc.Lines() {
+		field, _ := line.Field(0)
+		if field.Value != expected[i] {
+			t.Error("expected", expected[i], "on line", i+1, "but got", field.Value)
+		}
+	}
+	if line, _ := doc.Line(2); line.LineNumber() != 2 {
+		t.Error("expected the pinned line to be re-indexed as line 2 but got", line.LineNumber())
+	}
+}
+
+func TestRenameColumn(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Age", "City")...)
+	doc.AppendLine(Fields("Scott", "33", "Austin")...)
+	doc.AddLine()
+	doc.AppendLine(Fields("Jane", "79", "Boston")...)
+
+	if err := doc.RenameColumn("Missing", "x"); err != ErrFieldNameNotFound {
+		t.Error("expected ErrFieldNameNotFound but got", err)
+	}
+	if err := doc.RenameColumn("Age", "Years"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(doc.Headers(), ",") != "Name,Years,City" {
+		t.Error("expected only Age to be renamed but got", doc.Headers())
+	}
+	for _, ln := range []int{1, 2, 4} {
+		line, _ := doc.Line(ln)
+		for i, exp := range []string{"Name", "Years", "City"} {
+			field, err := line.Field(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if field.FieldName != exp {
+				t.Errorf("line %d: expected field %d to be named %s but got %s", ln, i, exp, field.FieldName)
+			}
+		}
+	}
+	header, _ := doc.Line(1)
+	if field, _ := header.Field(1); field.Value != "Years" {
+		t.Error("expected the header value to be Years but got", field.Value)
+	}
+	if field, _ := header.Field(0); field.Value != "Name" {
+		t.Error("expected the first header to be unchanged but got", field.Value)
+	}
+}
+
+func TestSetMinColumnWidth(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Age", "City")...)
+	doc.AppendLine(Fields("Scott", "33", "Austin")...)
+	doc.SetPadding([]rune(" "))
+
+	if err := doc.SetMinColumnWidth(1, -1); err != ErrInvalidColumnWidth {
+		t.Error("expected ErrInvalidColumnWidth but got", err)
+	}
+	if err := doc.SetMinColumnWidth(-1, 3); err != ErrFieldIndexedNotFound {
+		t.Error("expected ErrFieldIndexedNotFound but got", err)
+	}
+	if err := doc.SetMinColumnWidth(1, 6); err != nil {
+		t.Fatal(err)
+	}
+	// narrower than the widest value so has no effect
+	if err := doc.SetMinColumnWidth(0, 2); err != nil {
+		t.Fatal(err)
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name  Age    City\n" +
+		"Scott 33     Austin\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestWriteVertical(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Favorite Color")...)
+	doc.AppendLine(Field("Scott Smith"), Null())
+
+	var buf strings.Builder
+	if err := WriteVertical(doc, &buf); err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name: \"Scott Smith\"\n" +
+		"Favorite Color: -\n" +
+		"\n"
+	if buf.String() != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, buf.String())
+	}
+}
+
+func TestSetColumnAlignment(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Item", "Price", "Note")...)
+	doc.AppendLine(Fields("Café", "1000.50", "x")...)
+	doc.AppendLine(Fields("🍰", "5", "y")...)
+	doc.SetColumnAlignment(1, AlignRight)
+	doc.SetColumnAlignment(2, AlignRight)
+
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Item    Price  Note\n" +
+		"Café  1000.50  x\n" +
+		"🍰           5  y\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestTrimColumn(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "City")...)
+	doc.AppendLine(Fields("  Scott  ", " Austin ")...)
+	doc.AppendLine(Null(), Field("\tBoston"))
+	doc.AppendLine(Fields("Jane", "Denver")...)
+
+	if err := doc.TrimColumn("Missing"); err != ErrFieldNameNotFound {
+		t.Error("expected ErrFieldNameNotFound but got", err)
+	}
+	if err := doc.TrimColumn("Name"); err != nil {
+		t.Fatal(err)
+	}
+	line, _ := doc.Line(2)
+	if name, _ := line.Field(0); name.Value != "Scott" {
+		t.Errorf("expected the name to be trimmed to 'Scott' but got '%s'", name.Value)
+	}
+	if city, _ := line.Field(1); city.Value != " Austin " {
+		t.Errorf("expected the city to be untouched but got '%s'", city.Value)
+	}
+	line, _ = doc.Line(3)
+	if name, _ := line.Field(0); !name.IsNull {
+		t.Error("expected the null name to stay null")
+	}
+	if w, _ := doc.MaxColumnWidth(0); w != 5 {
+		t.Error("expected the width of column 0 to be recalculated to 5 but got", w)
+	}
+}
+
+func TestMaxLineWidthWrapsWideLines(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "City", "Country", "Note")...)
+	doc.AppendLine(Fields("Scott", "Austin", "USA", "a rather long note")...)
+	doc.AppendLine(Fields("Jane", "Lyon", "France", "short")...)
+	doc.MaxLineWidth = 24
+
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   City    Country\n" +
+		"  > Note\n" +
+		"Scott  Austin  USA\n" +
+		`  > "a rather long note"` + "\n" +
+		"Jane   Lyon    France\n" +
+		"  > short\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestSortingNullAs(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Balance", "Name")...)
+	doc.AppendLine(Fields("18", "Scott")...)
+	doc.AppendLine(Null(), Field("Jane"))
+	doc.AppendLine(Fields("-5", "Patrick")...)
+	doc.AppendLine(Fields("3", "Zak")...)
+
+	sort := SortNumber("Balance")
+	sort.NullAs = "0"
+	if err := doc.SortBy(sort); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Name", "Patrick", "Jane", "Zak", "Scott"}
+	for i, line := range doc.Lines() {
+		field, _ := line.Field(1)
+		if field.Value != expected[i] {
+			t.Error("expected", expected[i], "on line", i+1, "but got", field.Value)
+		}
+	}
+	line, _ := doc.Line(3)
+	if field, _ := line.Field(0); !field.IsNull {
+		t.Error("expected the null to be kept in the document but got", field.Value)
+	}
+}
+
+func TestColumnGoLiteral(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Code", "Label")...)
+	doc.AppendLine(Fields("a", `say "hi"`)...)
+	doc.AddLine()
+	doc.AppendLine(Fields("b", "back\\slash")...)
+	doc.AppendLine(Field("c"), Null())
+
+	if _, err := doc.ColumnGoLiteral("Missing"); err != ErrFieldNameNotFound {
+		t.Error("expected ErrFieldNameNotFound but got", err)
+	}
+	v, err := doc.ColumnGoLiteral("Label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `[]string{"say \"hi\"", "back\\slash", ""}`
+	if v != exp {
+		t.Errorf("expected %s but got %s", exp, v)
+	}
+}
+
+type shortWriter struct{}
+
+func (shortWriter) Write(p []byte) (int, error) {
+	return len(p) / 2, nil
+}
+
+func TestWriteTo(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Age")...)
+	doc.AppendLine(Fields("Scott", "33")...)
+
+	var _ io.WriterTo = doc
+	var buf strings.Builder
+	n, err := doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Age\nScott  33\n"
+	if buf.String() != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, buf.String())
+	}
+	if n != int64(len(exp)) {
+		t.Error("expected", len(exp), "bytes written but got", n)
+	}
+
+	doc.ResetWrite()
+	n, err = doc.WriteTo(shortWriter{})
+	if err != io.ErrShortWrite {
+		t.Error("expected io.ErrShortWrite but got", err)
+	}
+	if n != int64(len("Name   Age\n")/2) {
+		t.Error("expected the bytes of the short write to be counted but got", n)
+	}
+}
+
+func TestFilterByComment(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Age")...)
+	line, _ := doc.AppendLine(Fields("Scott", "33")...)
+	line.UpdateComment("reviewed")
+	doc.AppendLine(Fields("Jane", "79")...)
+	line, _ = doc.AppendLine(Fields("John", "41")...)
+	line.UpdateComment("needs review")
+	line, _ = doc.AppendLine(Fields("Mary", "12")...)
+	line.UpdateComment("skip")
+
+	filtered, err := doc.FilterByComment(func(comment string) bool {
+		return strings.Contains(comment, "review")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := filtered.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Age\n" +
+		"Scott  33  #reviewed\n" +
+		"John   41  #needs review\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+	if doc.LineCount() != 5 {
+		t.Error("expected the original document to be unchanged but has", doc.LineCount(), "lines")
+	}
+}
+
+func TestAppendValuesNull(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendValues("First", "Second", "Third")
+	line, err := doc.AppendValues("a", "-", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line.FieldCount() != 3 {
+		t.Fatal("expected 3 fields but got", line.FieldCount())
+	}
+	if field, _ := line.Field(1); !field.IsNull {
+		t.Error("expected the second field to be null")
+	}
+	if field, _ := line.Field(2); field.Value != "b" {
+		t.Error("expected the third field to be b but got", field.Value)
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Key", "Dev", "Prod")...)
+	line, _ := doc.AppendLine(Fields("host", "localhost", "example.com")...)
+	line.UpdateComment("dropped")
+	doc.AddLine()
+	doc.AppendLine(Fields("port", "8080", "443")...)
+	doc.AppendLine(Fields("debug", "true")...)
+
+	if _, err := (&Document{}).Transpose(); err != ErrNonTabularDocument {
+		t.Error("expected ErrNonTabularDocument but got", err)
+	}
+	transposed, err := doc.Transpose()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(transposed.Headers(), ",") != "Key,host,port,debug" {
+		t.Error("expected the first column to become the headers but got", transposed.Headers())
+	}
+	d, err := transposed.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Key   host         port  debug\n" +
+		"Dev   localhost    8080  true\n" +
+		"Prod  example.com  443   -\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestNormalizeNulls(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Nickname", "Age")...)
+	doc.AppendLine(Field("Scott"), Field(""), Field("33"))
+	doc.AppendLine(Field("Jane"), Null(), Field("79"))
+
+	if err := doc.NullifyEmpties(); err != nil {
+		t.Fatal(err)
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Nickname  Age\n" +
+		"Scott  -         33\n" +
+		"Jane   -         79\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+
+	doc.ResetWrite()
+	if err := doc.EmptyNulls(); err != nil {
+		t.Fatal(err)
+	}
+	d, err = doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = "Name   Nickname  Age\n" +
+		"Scott  \"\"        33\n" +
+		"Jane   \"\"        79\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestColumnStats(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Age", "Name")...)
+	doc.AppendLine(Field("33"), Field("Scott"))
+	doc.AppendLine(Null(), Field("Jane"))
+	doc.AppendLine(Field("79"), Field("John"))
+	doc.AppendLine(Field("2"), Field("Eva"))
+
+	stats, err := doc.ColumnStats("Age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := ColumnStats{Count: 3, NullCount: 1, Numeric: true, Min: 2, Max: 79, Sum: 114, Mean: 38}
+	if stats != exp {
+		t.Errorf("expected %+v but got %+v", exp, stats)
+	}
+
+	stats, err = doc.ColumnStats("Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Numeric || stats.Count != 4 {
+		t.Errorf("expected 4 non-numeric values but got %+v", stats)
+	}
+
+	if _, err := doc.ColumnStats("Missing"); err != ErrFieldNameNotFound {
+		t.Error("expected ErrFieldNameNotFound but got", err)
+	}
+
+	doc.AppendLine(Field("NaN"), Field("Inf"))
+	stats, err = doc.ColumnStats("Age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = ColumnStats{Count: 4, NullCount: 1}
+	if stats != exp {
+		t.Errorf("expected NaN to make the column non-numeric, %+v but got %+v", exp, stats)
+	}
+}
+
+func TestColumnContains(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Age")...)
+	doc.AppendLine(Fields("Scott", "33")...)
+	doc.AppendLine(Null(), Field("79"))
+
+	if ok, err := doc.ColumnContains("Name", "Scott"); err != nil || !ok {
+		t.Error("expected Scott to be in the Name column but got", ok, err)
+	}
+	if ok, err := doc.ColumnContains("Name", "Jane"); err != nil || ok {
+		t.Error("expected Jane to not be in the Name column but got", ok, err)
+	}
+	if ok, _ := doc.ColumnContains("Name", ""); ok {
+		t.Error("expected a null value to not equal an empty string")
+	}
+	if _, err := doc.ColumnContains("Country", "US"); err != ErrFieldNameNotFound {
+		t.Error("expected ErrFieldNameNotFound but got", err)
+	}
+}
+
+func TestAlignComments(t *testing.T) {
+	doc := NewDocument()
+	doc.AlignComments = true
+	doc.AppendLine(Fields("Name", "Age")...)
+	line, _ := doc.AppendLine(Fields("Scott", "33")...)
+	line.UpdateComment("a")
+	line, _ = doc.AppendLine(Fields("Johnathan", "1234")...)
+	line.UpdateComment("b")
+	line, _ = doc.AddLine()
+	line.UpdateComment("only a comment")
+	doc.AppendLine(Fields("Jane", "7")...)
+
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name       Age\n" +
+		"Scott      33    #a\n" +
+		"Johnathan  1234  #b\n" +
+		"#only a comment\n" +
+		"Jane       7\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestColumnUnique(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("City", "Name")...)
+	doc.AppendLine(Fields("Denver", "Scott")...)
+	doc.AppendLine(Fields("Boston", "Jane")...)
+	doc.AppendLine(Null(), Field("John"))
+	doc.AppendLine(Fields("Denver", "Eva")...)
+	doc.AppendLine(Fields("", "Max")...)
+
+	values, err := doc.ColumnUnique("City")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(values, []string{"Denver", "Boston", ""}) {
+		t.Error("expected the distinct values in first-seen order but got", values)
+	}
+	if _, err := doc.ColumnUnique("Country"); err != ErrFieldNameNotFound {
+		t.Error("expected ErrFieldNameNotFound but got", err)
+	}
+}
+
+func TestSetQuoting(t *testing.T) {
+	newDoc := func() *Document {
+		doc := NewDocument()
+		doc.AppendLine(Fields("Name", "Age", "City")...)
+		doc.AppendLine(Field("Scott"), Field("33"), Field("New York"))
+		doc.AppendLine(Field("Jane"), Null(), Field("Denver"))
+		return doc
+	}
+	tests := []struct {
+		quoting Quoting
+		exp     string
+	}{
+		{QuoteMinimal, "Name   Age  City\n" +
+			"Scott  33   \"New York\"\n" +
+			"Jane   -    Denver\n"},
+		{QuoteAll, "\"Name\"   \"Age\"  \"City\"\n" +
+			"\"Scott\"  \"33\"   \"New York\"\n" +
+			"\"Jane\"   -      \"Denver\"\n"},
+		{QuoteNonNumeric, "\"Name\"   \"Age\"  \"City\"\n" +
+			"\"Scott\"  33     \"New York\"\n" +
+			"\"Jane\"   -      \"Denver\"\n"},
+	}
+	for _, test := range tests {
+		doc := newDoc()
+		doc.SetQuoting(test.quoting)
+		d, err := doc.WriteAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(d) != test.exp {
+			t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", test.exp, string(d))
+		}
+	}
+
+	// text that strconv.ParseFloat accepts is still quoted, only decimal numbers are not
+	doc := NewDocument()
+	doc.AppendLine(Fields("A", "B", "C", "D")...)
+	doc.AppendLine(Fields("NaN", "Infinity", "1.5e3", "0x1p-2")...)
+	doc.SetQuoting(QuoteNonNumeric)
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "\"A\"    \"B\"         \"C\"    \"D\"\n" +
+		"\"NaN\"  \"Infinity\"  1.5e3  \"0x1p-2\"\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestReverseColumns(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Age", "City")...)
+	line, _ := doc.AppendLine(Fields("Scott", "33", "Boston")...)
+	line.UpdateComment("reviewed")
+	doc.AppendLine(Fields("Johnathan", "79", "Denver")...)
+	doc.SetColumnAlignment(1, AlignRight)
+
+	if err := doc.ReverseColumns(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(doc.Headers(), ",") != "City,Age,Name" {
+		t.Error("expected the headers to be reversed but got", doc.Headers())
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "City    Age  Name\n" +
+		"Boston   33  Scott  #reviewed\n" +
+		"Denver   79  Johnathan\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestGroupRows(t *testing.T) {
+	doc := NewDocument()
+	doc.GroupRows = 2
+	doc.AppendLine(Fields("Name", "Age")...)
+	for _, name := range []string{"Ann", "Bob", "Cat", "Dan", "Eve"} {
+		doc.AppendLine(Fields(name, "1")...)
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name  Age\n" +
+		"Ann   1\nBob   1\n\n" +
+		"Cat   1\nDan   1\n\n" +
+		"Eve   1\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+
+	doc.ResetWrite()
+	doc.GroupRows = 4
+	doc.GroupSeparator = "# ----"
+	d, err = doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = "Name  Age\n" +
+		"Ann   1\nBob   1\nCat   1\nDan   1\n# ----\n" +
+		"Eve   1\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestClone(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Age")...)
+	doc.AppendLine(Fields("Scott", "33")...)
+	doc.AppendLine(Fields("Jane", "79")...)
+	original, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.ResetWrite()
+
+	clone := doc.Clone()
+	if err := clone.SortBy(SortDesc("Age")); err != nil {
+		t.Fatal(err)
+	}
+	line, _ := clone.Line(2)
+	if err := line.UpdateField(0, "Janet Smith"); err != nil {
+		t.Fatal(err)
+	}
+	header, _ := clone.Line(1)
+	if !header.IsHeader() {
+		t.Error("expected the first line of the clone to be the header")
+	}
+	if _, err := clone.AppendLine(Fields("John", "41")...); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(d) != string(original) {
+		t.Errorf("expected the original to be unchanged but got \n%s\ninstead", string(d))
+	}
+	d, err = clone.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name           Age\n" +
+		"\"Janet Smith\"  79\n" +
+		"Scott          33\n" +
+		"John           41\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestMapColumns(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Code", "Name")...)
+	doc.AppendLine(Fields("US", "United States")...)
+	doc.AppendLine(Fields("FR", "France")...)
+	doc.AppendLine(Null(), Field("Nowhere"))
+
+	m, err := doc.MapColumns("Code", "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m["US"] != "United States" || m["FR"] != "France" {
+		t.Error("expected a map of the codes to the names but got", m)
+	}
+
+	doc.AppendLine(Fields("FR", "République française")...)
+	if _, err := doc.MapColumns("Code", "Name"); !errors.Is(err, ErrDuplicateKey) {
+		t.Error("expected ErrDuplicateKey but got", err)
+	}
+	m, err = doc.MapColumns("Code", "Name", MapLastWins())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["FR"] != "République française" {
+		t.Error("expected the last value to win but got", m["FR"])
+	}
+	if _, err := doc.MapColumns("Code", "Capital"); err != ErrFieldNameNotFound {
+		t.Error("expected ErrFieldNameNotFound but got", err)
+	}
+}
+
+func TestNullString(t *testing.T) {
+	doc := NewDocument()
+	doc.NullString = "NULL"
+	doc.AppendValues("Name", "Score", "City")
+	doc.AppendValues("Scott", "-", "NULL")
+	doc.AppendLine(Field("Jane"), Field("-5"), Field("NULL"))
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Score  City\n" +
+		"Scott  -      NULL\n" +
+		"Jane   -5     \"NULL\"\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestControlChars(t *testing.T) {
+	newDoc := func(cc ControlChars) *Document {
+		doc := NewDocument()
+		doc.ControlChars = cc
+		doc.AppendLine(Fields("Name", "Notes", "Path")...)
+		doc.AppendLine(Field("Scott"), Field("a\tb\x00"), Field(`C:\tmp`))
+		doc.AppendLine(Field("Jane"), Field("bell\a"), Null())
+		return doc
+	}
+
+	_, err := newDoc(ControlCharsReject).WriteAll()
+	if !errors.Is(err, ErrControlCharacter) {
+		t.Fatal("expected ErrControlCharacter but got", err)
+	}
+	if err.Error() != "field 1 of line 2 contains the control character U+0009" {
+		t.Error("unexpected error message", err)
+	}
+
+	d, err := newDoc(ControlCharsEscape).WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Notes           Path\n" +
+		`Scott  a\u0009b\u0000  C:\\tmp` + "\n" +
+		`Jane   bell\u0007      -` + "\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestDocumentValidate(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendValues("Name", "Age", "City")
+	doc.AppendValues("Scott", "33", "Boston")
+	doc.AddLine()
+	line, _ := doc.AddLine()
+	line.UpdateComment("comment only")
+	ok, errs := doc.Validate()
+	if !ok || len(errs) != 0 {
+		t.Fatal("expected the document to be valid but got", errs)
+	}
+
+	doc.AppendValues("John")
+	doc.AppendValues("Jane", "79", "Denver")
+	doc.AppendValues("Eve", "5")
+	ok, errs = doc.Validate()
+	if ok {
+		t.Error("expected the document to be invalid")
+	}
+	exp := []string{
+		"line 5 does not have the correct number of fields 1/3 (current/expected)",
+		"line 7 does not have the correct number of fields 2/3 (current/expected)",
+	}
+	if len(errs) != len(exp) {
+		t.Fatalf("expected %d errors but got %v", len(exp), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != exp[i] {
+			t.Errorf("expected %q but got %q", exp[i], err.Error())
+		}
+	}
+}
+
+func TestNullHeaders(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Field("Name"), Null(), Field(""))
+	doc.AppendValues("Scott", "33", "Boston")
+	if strings.Join(doc.Headers(), ",") != "Name,col2,col3" {
+		t.Error("expected the null and empty headers to be named by position but got", doc.Headers())
+	}
+	line, _ := doc.Line(2)
+	field, err := line.Field(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.FieldName != "col2" {
+		t.Error("expected the field to be named col2 but got", field.FieldName)
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   -   \"\"\n" +
+		"Scott  33  Boston\n"
+	if string(d) != exp {
+		t.Errorf("expected the headers to be written as is \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestSortByFunc(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendValues("Name", "Team", "Points")
+	doc.AppendValues("Scott", "Red", "12")
+	doc.AppendValues("Jane", "Blue", "30")
+	doc.AppendValues("John", "Red", "30")
+	doc.AppendValues("Eve", "Blue", "7")
+	err := doc.SortByFunc(func(a, b Line) int {
+		ta, _ := a.FieldByName("Team")
+		tb, _ := b.FieldByName("Team")
+		if c := strings.Compare(ta.Value, tb.Value); c != 0 {
+			return c
+		}
+		pa, _ := a.FieldByName("Points")
+		pb, _ := b.FieldByName("Points")
+		na, _ := strconv.Atoi(pa.Value)
+		nb, _ := strconv.Atoi(pb.Value)
+		return nb - na
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Team  Points\n" +
+		"Jane   Blue  30\n" +
+		"Eve    Blue  7\n" +
+		"John   Red   30\n" +
+		"Scott  Red   12\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+	line, _ := doc.Line(2)
+	if name, _ := line.FieldByName("Name"); name.Value != "Jane" || line.LineNumber() != 2 {
+		t.Errorf("expected line 2 to be renumbered to Jane but got %s on line %d", name.Value, line.LineNumber())
+	}
+
+	doc.Tabular = false
+	if err = doc.SortByFunc(func(a, b Line) int { return 0 }); err != ErrCannotSortNonTabularDocument {
+		t.Error("expected ErrCannotSortNonTabularDocument but got", err)
+	}
+}
+
+func TestSetColumnOrder(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendValues("Name", "Age", "City", "Country")
+	line, _ := doc.AppendValues("Scott", "33", "Boston", "USA")
+	line.UpdateComment("first")
+	doc.AppendValues("Jane", "-", "Toronto", "Canada")
+	if err := doc.SetColumnOrder("Country", "Name"); err != nil {
+		t.Fatal(err)
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Country  Name   Age  City\n" +
+		"USA      Scott  33   Boston  #first\n" +
+		"Canada   Jane   -    Toronto\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+	if strings.Join(doc.Headers(), ",") != "Name,Age,City,Country" {
+		t.Error("expected the stored column order to be unchanged but got", doc.Headers())
+	}
+
+	if err = doc.SetColumnOrder("Zip"); !errors.Is(err, ErrFieldNameNotFound) {
+		t.Error("expected ErrFieldNameNotFound but got", err)
+	}
+	if err = doc.SetColumnOrder(); err != nil {
+		t.Fatal(err)
+	}
+	d, _ = doc.WriteAll()
+	if !strings.HasPrefix(string(d), "Name   Age  City     Country\n") {
+		t.Error("expected the current column order after resetting but got\n", string(d))
+	}
+}
+
+func TestSetColumnFormat(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendValues("Item", "Price", "Qty")
+	doc.AppendValues("Tea", "3.5", "12")
+	doc.AppendValues("Kettle", "1249", "1")
+	doc.AppendValues("Gift", "n/a", "-")
+	doc.SetColumnFormat(1, "$%.2f")
+	doc.SetColumnAlignment(1, AlignRight)
+	doc.SetColumnFormat(2, "%03d")
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Item       Price  Qty\n" +
+		"Tea        $3.50  012\n" +
+		"Kettle  $1249.00  001\n" +
+		"Gift         n/a  -\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+	line, _ := doc.Line(2)
+	if price, _ := line.Field(1); price.Value != "3.5" {
+		t.Error("expected the stored value to be unchanged but got", price.Value)
+	}
+
+	doc.SetColumnFormat(1, "")
+	doc.SetColumnFormat(2, "")
+	doc.SetColumnAlignment(1, AlignLeft)
+	d, _ = doc.WriteAll()
+	if !strings.HasPrefix(string(d), "Item    Price  Qty\nTea     3.5    12\n") {
+		t.Error("expected the format to be removed but got\n", string(d))
+	}
+
+	for _, format := range []string{"%s", "%v", "%d %d", "%.2f%", "no verb"} {
+		if err := doc.SetColumnFormat(1, format); !errors.Is(err, ErrInvalidNumberFormat) {
+			t.Errorf("expected ErrInvalidNumberFormat for %q but got %v", format, err)
+		}
+	}
+}
+
+func TestSetColumnFormatMovesWithColumn(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendValues("Item", "Price", "Qty")
+	doc.AppendValues("Tea", "3.5", "12")
+	if err := doc.SetColumnFormat(2, "%03d"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReverseColumns(); err != nil {
+		t.Fatal(err)
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "Qty  Price  Item\n012  3.5    Tea\n"; string(d) != exp {
+		t.Errorf("expected the format to move with the Qty column \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+
+	doc = NewDocument()
+	doc.AppendValues("Item", "Price", "Qty")
+	doc.AppendValues("Tea", "3.5", "12")
+	doc.SetColumnFormat(1, "%.2f")
+	doc.SetColumnFormat(2, "%03d")
+	if err := doc.DeleteColumnAt(1); err != nil {
+		t.Fatal(err)
+	}
+	d, err = doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "Item  Qty\nTea   012\n"; string(d) != exp {
+		t.Errorf("expected the format of the deleted column to be dropped \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestDocumentEqual(t *testing.T) {
+	build := func() *Document {
+		doc := NewDocument()
+		doc.AppendValues("Name", "Age")
+		doc.AppendValues("Scott", "33")
+		line, _ := doc.AddLine()
+		line.Append("Jane")
+		line.AppendNull()
+		line.UpdateComment("no age")
+		return doc
+	}
+	a, b := build(), build()
+	b.SetPadding([]rune{' '})
+	if ok, diff := a.Equal(b); !ok {
+		t.Fatal("expected the documents to be equal but got", diff)
+	}
+
+	tests := []struct {
+		edit func(doc *Document)
+		diff string
+	}{
+		{func(doc *Document) { doc.UpdateHeader(1, "Years") }, "headers differ, Name Age and Name Years"},
+		{func(doc *Document) { doc.AppendValues("John", "5") }, "line counts differ, 3 and 4"},
+		{func(doc *Document) { doc.lines[1].UpdateField(1, "34") }, "line 2, field 2: values differ, 33 and 34"},
+		{func(doc *Document) {
+			doc.lines[2].(*documentLine).fields[1] = internal.Field{FieldName: "Age", Value: "-"}
+		}, `line 3, field 2: values differ, - and "-"`},
+		{func(doc *Document) { doc.lines[2].UpdateComment("unknown") }, `line 3: comments differ, "no age" and "unknown"`},
+	}
+	for _, test := range tests {
+		b := build()
+		test.edit(b)
+		ok, diff := a.Equal(b)
+		if ok || diff != test.diff {
+			t.Errorf("expected %q but got %q", test.diff, diff)
+		}
+	}
+}
+
+func TestSetRawColumn(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendLine(Fields("Name", "Token", "Note")...)
+	doc.AppendLine(Fields("Scott", `"a b"`, "x y")...)
+	doc.AppendLine(Fields("Jane", "-", "-")...)
+	doc.AppendLine(Field("John"), Null(), Field(`"q"`))
+	doc.SetRawColumn(1)
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Token  Note\n" +
+		"Scott  \"a b\"  \"x y\"\n" +
+		"Jane   -      \"-\"\n" +
+		"John   -      \"\"\"q\"\"\"\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+
+	doc.ResetWrite()
+	if err := doc.ReverseColumns(); err != nil {
+		t.Fatal(err)
+	}
+	d, err = doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = "Note     Token  Name\n" +
+		"\"x y\"    \"a b\"  Scott\n" +
+		"\"-\"      -      Jane\n" +
+		"\"\"\"q\"\"\"  -      John\n"
+	if string(d) != exp {
+		t.Errorf("expected the raw column to move with the Token column \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+
+	doc.ResetWrite()
+	if err := doc.DeleteColumnAt(0); err != nil {
+		t.Fatal(err)
+	}
+	d, _ = doc.WriteAll()
+	if !strings.HasPrefix(string(d), "Token  Name\n\"a b\"  Scott\n") {
+		t.Error("expected the raw column to shift with the Token column but got\n", string(d))
+	}
+
+	doc.ResetWrite()
+	doc.AppendLine(Fields("", "Eve")...)
+	if _, err := doc.WriteAll(); !errors.Is(err, ErrEmptyRawValue) {
+		t.Error("expected ErrEmptyRawValue writing an empty raw value but got", err)
+	}
+}
+
+func TestWriteWithoutEmitHeaders(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendValues("Name", "Age")
+	doc.AppendValues("Scott", "33")
+	doc.EmitHeaders = false
+	doc.EmitBOM = true
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(d) != "\xef\xbb\xbfScott  33\n" {
+		t.Errorf("expected only the data line after the byte order mark but got %q", string(d))
+	}
+	doc.currentWriteLine = 0
+	if _, err := doc.Write(); err != ErrOmitHeaders {
+		t.Error("expected ErrOmitHeaders writing the header line but got", err)
+	}
+	if line, err := doc.Write(); err != nil || string(line) != "Scott  33\n" {
+		t.Errorf("expected the next write to be the data line but got %q %v", string(line), err)
+	}
+}
+
+func TestCountWhere(t *testing.T) {
+	doc := NewDocument()
+	doc.AppendValues("Name", "City")
+	doc.AppendValues("Scott", "Boston")
+	doc.AppendValues("Jane", "Denver")
+	doc.AddLine()
+	line, _ := doc.AddLine()
+	line.UpdateComment("Boston")
+	doc.AppendValues("John", "Boston")
+	inBoston := func(line Line) bool {
+		city, err := line.FieldByName("City")
+		return err == nil && city.Value == "Boston"
+	}
+	if n := doc.CountWhere(inBoston); n != 2 {
+		t.Error("expected 2 lines in Boston but got", n)
+	}
+	if n := doc.CountWhere(func(line Line) bool { return true }); n != 3 {
+		t.Error("expected only the 3 data lines to be counted but got", n)
+	}
+}
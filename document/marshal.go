@@ -1,9 +1,14 @@
 package document
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"reflect"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/campfhir/wsv/internal"
@@ -12,6 +17,8 @@ import (
 var (
 	ErrUnsupportMarshalType = errors.New("unsupported type to marshal")
 	ErrNoDataMarshalled     = errors.New("no data marshalled")
+	ErrHeaderMismatch       = errors.New("marshalled headers do not match the document headers")
+	ErrFilterType           = errors.New("the filter does not accept the type of the marshalled elements")
 )
 
 type MarshalWSV interface {
@@ -21,11 +28,50 @@ type MarshalWSV interface {
 type row struct {
 	fields  []internal.Field
 	comment string
+	// field names with the `skipempty` attribute
+	skipEmpty []string
 }
 
-func marshalRow(v reflect.Value) (*row, error) {
+// An option of [MarshalWithOptions], either a sort such as [Sort] applied to the marshalled document or an option
+// that configures the marshal such as [WithFilter]
+type MarshalOption interface {
+	apply(opts *marshalOptions)
+}
+
+// an option that configures the marshal rather than sorting
+type marshalOption func(opts *marshalOptions)
+
+func (o marshalOption) apply(opts *marshalOptions) {
+	o(opts)
+}
+
+// options applied to every element marshalled
+type marshalOptions struct {
+	// the `func(T) bool` filters of the elements to marshal
+	filters []any
+	// the format of bool fields without a `format:` attribute
+	boolFormat string
+	// leave the header line out of the output
+	omitHeaders bool
+	// the sorts applied to the marshalled document in order
+	sorts []*SortOption
+}
+
+// returns the marshal options set among the options
+func marshalOptionsOf(options []MarshalOption) marshalOptions {
+	var opts marshalOptions
+	for _, option := range options {
+		if option != nil {
+			option.apply(&opts)
+		}
+	}
+	return opts
+}
+
+func marshalRow(v reflect.Value, opts marshalOptions) (*row, error) {
 	fields := make([]internal.Field, 0, v.NumField())
 	var comment string
+	var skipEmpty []string
 
 	for i := 0; i < v.NumField(); i++ {
 		fieldValue := v.Field(i)
@@ -36,11 +82,29 @@ func marshalRow(v reflect.Value) (*row, error) {
 			continue
 		}
 
+		// Flatten embedded structs without a `wsv` tag, a nil embedded pointer emits nulls for its columns
+		if _, tagged := fieldType.Tag.Lookup("wsv"); fieldType.Anonymous && !tagged && isEmbeddedStruct(fieldType.Type) {
+			embedded, err := marshalEmbedded(fieldValue, opts)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embedded.fields...)
+			skipEmpty = append(skipEmpty, embedded.skipEmpty...)
+			if embedded.comment != "" {
+				comment = appendComment(comment, embedded.comment)
+			}
+			continue
+		}
+
 		// Parse tag
-		key, isComment, format, literalEmptyField := internal.ParseWSVTag(fieldType)
-		if key == "-" && !literalEmptyField {
+		tag := internal.ParseWSVTag(fieldType)
+		key, isComment, format := tag.Key, tag.IsComment, tag.Format
+		if key == "-" && !tag.LiteralEmptyField {
 			continue
 		}
+		if tag.SkipEmpty && !isComment {
+			skipEmpty = append(skipEmpty, key)
+		}
 		// Custom marshaller
 		if fieldValue.CanAddr() {
 			if u, ok := fieldValue.Addr().Interface().(MarshalWSV); ok {
@@ -62,6 +126,11 @@ func marshalRow(v reflect.Value) (*row, error) {
 			}
 		}
 
+		if tag.OmitEmpty && !isComment && isEmptyValue(fieldValue) {
+			fields = append(fields, internal.Field{FieldName: key, IsNull: true, FieldIndex: i})
+			continue
+		}
+
 		// Deref pointers/interfaces
 		fieldValue, isNil := deref(fieldValue)
 		if isNil {
@@ -72,6 +141,21 @@ func marshalRow(v reflect.Value) (*row, error) {
 			continue
 		}
 
+		// Fallback to encoding.TextMarshaler
+		if m, ok := textMarshaler(fieldValue); ok {
+			b, err := m.MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			val := string(b)
+			if isComment {
+				comment = appendComment(comment, val)
+				continue
+			}
+			fields = append(fields, internal.Field{FieldName: key, Value: val, FieldIndex: i})
+			continue
+		}
+
 		// Handle concrete kinds
 		switch fieldValue.Kind() {
 		case reflect.String:
@@ -109,8 +193,19 @@ func marshalRow(v reflect.Value) (*row, error) {
 			}
 			fields = append(fields, internal.Field{FieldName: key, Value: val, FieldIndex: i})
 
+		case reflect.Complex64, reflect.Complex128:
+			val := strconv.FormatComplex(fieldValue.Complex(), 'g', -1, fieldValue.Type().Bits())
+			if format != "" {
+				val = fmt.Sprintf(format, fieldValue.Complex())
+			}
+			if isComment {
+				comment = appendComment(comment, val)
+				continue
+			}
+			fields = append(fields, internal.Field{FieldName: key, Value: val, FieldIndex: i})
+
 		case reflect.Bool:
-			format = internal.DefaultIfEmpty(format, "True|False")
+			format = internal.DefaultIfEmpty(format, internal.DefaultIfEmpty(opts.boolFormat, "True|False"))
 			val := internal.FormatBool(fieldValue.Bool(), format)
 			if isComment {
 				comment = appendComment(comment, val)
@@ -137,7 +232,70 @@ func marshalRow(v reflect.Value) (*row, error) {
 		}
 	}
 
-	return &row{fields, comment}, nil
+	return &row{fields, comment, skipEmpty}, nil
+}
+
+// returns true for the zero value of the non-pointer kinds supported by the `omitempty` attribute
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return t.IsZero()
+		}
+	}
+	return false
+}
+
+// returns true for a struct or a pointer to a struct, other than time.Time, that does not implement [MarshalWSV]
+func isEmbeddedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(reflect.TypeOf((*MarshalWSV)(nil)).Elem())
+}
+
+func marshalEmbedded(v reflect.Value, opts marshalOptions) (*row, error) {
+	if v.Kind() != reflect.Ptr {
+		return marshalRow(v, opts)
+	}
+	if !v.IsNil() {
+		return marshalRow(v.Elem(), opts)
+	}
+	r, err := marshalRow(reflect.New(v.Type().Elem()).Elem(), opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range r.fields {
+		r.fields[i] = internal.Field{FieldName: r.fields[i].FieldName, IsNull: true, FieldIndex: r.fields[i].FieldIndex}
+	}
+	r.comment = ""
+	return r, nil
+}
+
+// returns the [encoding.TextMarshaler] of the value, time.Time is excluded since it is formatted with the `format:` attribute
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		return nil, false
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return m, true
+	}
+	if v.CanAddr() {
+		m, ok := v.Addr().Interface().(encoding.TextMarshaler)
+		return m, ok
+	}
+	return nil, false
 }
 
 func callCustomMarshaller(u MarshalWSV, format string) (val string, isNull bool, err error) {
@@ -172,128 +330,246 @@ func appendComment(existing, newVal string) string {
 	return existing + " " + newVal
 }
 
-// Marshal returns a WSV encoding of s with the option to sort by columns.
-//
-// Marsal iterates over the elements of s. For each element of s it iterates over the fields of in the s[n].
-//
-// If a field in the s[n] implements [MarshalWSV] it will call the [MarshalWSV.MarshalWSV()]. Otherwise it will
-// use the default implementation.
-//
-// Marshal uses the `wsv` tag of fields within s[n] in following format: `wsv:"[field name][,format:[[string]][,comment]"`
-//
-// `field name` attribute can be empty and will take the name of the exported field.
-//
-// `format:` uses the value immediately after the colon `:`, until the end of the tag or a comma `,` unless enclosed in single quotes `'`.
-//
-// Struct tags with the `comment` attribute will be appended to the end of the line as comment. Subsequent fields with the `comment` attribute will be appended to previous comment
-// on the same line in the order declared in the struct with a single space ` ` between each field. Empty or nil values will not be appended.
-// The `field name` is ignored but must contain a comma `,` before.
-//
-// Example:
-//
-//	type User struct {
-//	  LastLogin time.Time `wsv:",comment"`
-//	  Points float64 `wsv:",format:%.4f,comment"`
-//	}
-//
-// Values will parsed with their formats or call [MarshalWSV.MarshalWSV()] prior to appending comments. See below for details about the `format`
-//
-// All exported fields in the struct s[n] will try to marshal unless a specific `wsv` tag with a field name of `-` is provided.
-// If the field name name is expect to literally be `-` there needs to be comma `,` to follow.
-//
-// Supports `string`, `int`, `bool`, `float`, `time.Time`.
-//
-// Fields with the type of `string` do not support the `format:` attribute in the struct tag and will just be ignored if specified.
-//
-// Fields with the type of `int` can alter their byte representation with the `format:` attribute in the struct tag.
-// The format is in the format of `fmt.Sprintf` and the default is `%d`.
-//
-// Example:
-//
-//	type Person struct {
-//	   Age int `wsv:"age,format:%d"`
-//	}
-//
-// Fields with type `bool` can also alter their byte representation with the `format:` attribute in the struct tag.
-// The format is template `true|false` with the left side of the `|` representing the literal value of `true` and the right side representing the literal value of `false`. The default is `True|False`.
-//
-// Example:
-//
-//	type User struct {
-//	  IsAdmin bool `wsv:"Admin,format:yes|no"`
-//	}
-//
-// Field with type `float` can alter their byte representation with the `format:` attribute in the struct tag.
-// The format is in the format of `fmt.Sprintf` and the default is `%.2f`
-//
-//	type Employee struct {
-//	  Salary float32 `wsv:"Weekly Salary,format:%.2f"`
-//	}
-//
-// Field with type `time.Time` can alter their byte representation with the `format:` attribute in the struct tag.
-// The format is in the format of `time.Format` and the default is `time.RFC3339`
-// The time can be written a literal string layout `2006-01-02` or using a the following shorthand values:
-//
-// * layout
-//
-// * ansic
-//
-// * unixdate
-//
-// * rubydate
-//
-// * rfc822
-//
-// * rfc822z
-//
-// * rfc850
-//
-// * rfc1123
-//
-// * rfc1123z
-//
-// * rfc3339
-//
-// * rfc3339nano
-//
-// * kitchen
-//
-// * stamp
-//
-// * stampmilli
-//
-// * stampmicro
-//
-// * stampnano
-//
-// * datetime
-//
-// * dateonly
-//
-// * date
-//
-// * timeonly
-//
-// * time
-//
-// If you need a format with `,` you can escape the `,` by wrapping the `format` in `'` single qoutes. For example `wsv:"field,format:'Jan 02, 2006'`.
-//
-// Example:
-//
-//	type TimeOff struct {
-//	  Date *time.Time `wsv:"PTO,format:'January 02, 2006'"`
-//	  Requested time.Time `wsv:"Requested,format:2006-01-02"`
-//	  Approved *time.Time `wsv:,format:rfc3339"`
-//	}
-func MarshalWithOptions[T any](s []T, options ...*internal.SortOption) ([]byte, error) {
+// MarshalWithOptions returns a WSV encoding of s the same way as [Marshal], applying the options to the marshal.
+// Sorts such as [Sort] are applied to the marshalled document in the order given, and options such as [WithFilter],
+// [WithBoolFormat] and [WithoutHeaders] configure how the elements are marshalled. Nil options are ignored.
+func MarshalWithOptions[T any](s []T, options ...MarshalOption) ([]byte, error) {
+	doc, err := marshalDocument(s, options...)
+	if err != nil {
+		return nil, err
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// MarshalWithHeaders returns a WSV encoding of s the same way as [Marshal], writing the header of each column
+// in `headers` with its display name instead. The keys of `headers` are the column names derived from the `wsv` tags,
+// the values the text written in the header line. Only the written header text changes, fields are bound to columns
+// by their tag names the same as [Marshal].
+//
+// Returns ErrFieldNameNotFound if a key of `headers` is not a marshalled column.
+func MarshalWithHeaders[T any](s []T, headers map[string]string) ([]byte, error) {
+	doc, err := marshalDocument(s)
+	if err != nil {
+		return nil, err
+	}
+	// resolve every column before renaming so a display name equal to another tag name does not shadow its column
+	cols := make(map[int]string, len(headers))
+	for name, display := range headers {
+		col := slices.Index(doc.headers, name)
+		if col < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrFieldNameNotFound, name)
+		}
+		cols[col] = display
+	}
+	for col, display := range cols {
+		if err = doc.UpdateHeader(col, display); err != nil {
+			return nil, err
+		}
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// An option for [MarshalWithOptions] to only marshal the elements `keep` returns true for, applied before the headers
+// are derived so filtering out every element returns ErrNoDataMarshalled. T must be the element type of the marshalled
+// slice or ErrFilterType is returned.
+func WithFilter[T any](keep func(T) bool) MarshalOption {
+	return marshalOption(func(opts *marshalOptions) {
+		opts.filters = append(opts.filters, keep)
+	})
+}
+
+// An option for [MarshalWithOptions] to write bool fields without a `format:` attribute with `format` instead of
+// `True|False`, in the same `true|false` template as the attribute. Fields with a `format:` attribute keep their own format.
+func WithBoolFormat(format string) MarshalOption {
+	return marshalOption(func(opts *marshalOptions) {
+		opts.boolFormat = format
+	})
+}
+
+// An option for [MarshalWithOptions] to write only the data lines without the header line, such as to append to an
+// existing file. The columns are still padded to the width of their headers so the lines align with a header written
+// by an earlier marshal of the same type.
+func WithoutHeaders() MarshalOption {
+	return marshalOption(func(opts *marshalOptions) {
+		opts.omitHeaders = true
+	})
+}
+
+// returns the elements of s kept by every filter
+func filterElements[T any](s []T, filters []any) ([]T, error) {
+	filtered := s
+	for _, filter := range filters {
+		keep, ok := filter.(func(T) bool)
+		if !ok {
+			return nil, ErrFilterType
+		}
+		filtered = slices.DeleteFunc(slices.Clone(filtered), func(v T) bool {
+			return !keep(v)
+		})
+	}
+	return filtered, nil
+}
+
+// marshals s into a new sorted document, see [MarshalWithOptions]
+func marshalDocument[T any](s []T, options ...MarshalOption) (*Document, error) {
+	opts := marshalOptionsOf(options)
+	s, err := filterElements(s, opts.filters)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := marshalRows(s, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) <= 0 {
+		return nil, ErrNoDataMarshalled
+	}
+	doc := NewDocument()
+	doc.EmitHeaders = !opts.omitHeaders
+	if err = appendHeaderRow(doc, rows[0]); err != nil {
+		return nil, err
+	}
+	if err = appendRows(doc, rows); err != nil {
+		return nil, err
+	}
+	if len(rows[0].skipEmpty) > 0 {
+		if err = doc.DeleteEmptyColumns(rows[0].skipEmpty...); err != nil {
+			return nil, err
+		}
+	}
+	err = doc.SortBy(opts.sorts...)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// MarshalSeq writes a WSV encoding of the elements of seq to w the same way as [Marshal], one line at a time as they
+// are consumed so neither the elements nor the output are held in memory. The headers are derived from the first element.
+// T can be a struct or a pointer to a struct, a nil element is written as a line of nulls.
+//
+// Since later elements are not known when a line is written, every line is padded to the widths of the header and the
+// first line and longer values push the rest of their line over. The `skipempty` attribute is not applied.
+//
+// Returns ErrNoDataMarshalled if seq is empty and ErrUnsupportMarshalType if T is not a struct.
+func MarshalSeq[T any](seq iter.Seq[T], w io.Writer) error {
+	et := reflect.TypeFor[T]()
+	if et.Kind() == reflect.Pointer {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		return ErrUnsupportMarshalType
+	}
+	var doc *Document
+	for v := range seq {
+		// marshal an addressable copy so fields with a pointer receiver for [MarshalWSV] are used like in a slice
+		r, err := marshalEmbedded(reflect.ValueOf(&v).Elem(), marshalOptions{})
+		if err != nil {
+			return err
+		}
+		if doc == nil {
+			doc = NewDocument()
+			if err = appendHeaderRow(doc, *r); err != nil {
+				return err
+			}
+		} else {
+			// the document only holds the header and the current line, the widths of the first line are kept as
+			// minimum widths so every line is padded the same
+			doc.ResetWrite()
+			doc.EmitHeaders = false
+			doc.lines = doc.lines[:1]
+			clear(doc.maxColumnWidth)
+		}
+		if err = appendRows(doc, []row{*r}); err != nil {
+			return err
+		}
+		var line []byte
+		for {
+			b, err := doc.Write()
+			line = append(line, b...)
+			if err == io.EOF {
+				break
+			}
+			if err != nil && err != ErrOmitHeaders {
+				return err
+			}
+		}
+		if _, err = w.Write(line); err != nil {
+			return err
+		}
+		if doc.EmitHeaders {
+			for col := range r.fields {
+				width, _ := doc.MaxColumnWidth(col)
+				if err = doc.SetMinColumnWidth(col, width); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if doc == nil {
+		return ErrNoDataMarshalled
+	}
+	return nil
+}
+
+// AppendMarshal marshals s the same way as [Marshal] and appends the resulting rows to the end of doc,
+// allowing a document to be built incrementally from multiple batches.
+//
+// If doc does not have any headers yet a header line is added first, otherwise the marshalled headers
+// must match the headers of doc in name and order or ErrHeaderMismatch is returned and nothing is appended.
+func AppendMarshal[T any](doc *Document, s []T) error {
+	if doc.startedWriting {
+		return &WriteError{err: ErrStartedToWrite, line: doc.currentWriteLine}
+	}
+	rows, err := marshalRows(s, marshalOptions{})
+	if err != nil {
+		return err
+	}
+	if len(rows) <= 0 {
+		return nil
+	}
+	if len(doc.headers) == 0 {
+		if err = appendHeaderRow(doc, rows[0]); err != nil {
+			return err
+		}
+	} else {
+		names := make([]string, len(rows[0].fields))
+		for i, field := range rows[0].fields {
+			names[i] = field.FieldName
+		}
+		if !slices.Equal(names, doc.headers) {
+			return ErrHeaderMismatch
+		}
+	}
+	return appendRows(doc, rows)
+}
+
+func marshalRows[T any](s []T, opts marshalOptions) ([]row, error) {
 	v_ := reflect.ValueOf(s)
 	t_ := reflect.TypeOf(s)
 	var rows []row
 	switch t_.Kind() {
 	case reflect.Slice:
+		// the elements can be a named or anonymous struct type, the struct tags are read the same for both
+		et := t_.Elem()
+		if et.Kind() == reflect.Pointer {
+			et = et.Elem()
+		}
+		if et.Kind() != reflect.Struct {
+			return nil, ErrUnsupportMarshalType
+		}
 		for i := range v_.Len() {
+			// pointer elements are dereferenced, a nil element emits a row of nulls the same as a nil embedded struct
 			v := v_.Index(i)
-			row, err := marshalRow(v)
+			row, err := marshalEmbedded(v, opts)
 			if err != nil {
 				return nil, err
 			}
@@ -301,58 +577,53 @@ func MarshalWithOptions[T any](s []T, options ...*internal.SortOption) ([]byte,
 		}
 	default:
 		return nil, ErrUnsupportMarshalType
-		// v_ = v_.Len()
 	}
+	return rows, nil
+}
 
-	if len(rows) <= 0 {
-		return nil, ErrNoDataMarshalled
-	}
-	doc := NewDocument()
+func appendHeaderRow(doc *Document, r row) error {
 	line, err := doc.AddLine()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	for _, field := range rows[0].fields {
+	for _, field := range r.fields {
 		if err = line.Append(field.FieldName); err != nil {
-			return nil, err
+			return err
 		}
 	}
+	return nil
+}
 
+func appendRows(doc *Document, rows []row) error {
 	for _, row := range rows {
-		line, err = doc.AddLine()
+		line, err := doc.AddLine()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		for _, field := range row.fields {
 			if field.IsNull {
 				if err = line.AppendNull(); err != nil {
-					return nil, err
+					return err
 				}
 				continue
 			}
 			if err = line.Append(field.Value); err != nil {
-				return nil, err
+				return err
 			}
 		}
 		line.UpdateComment(row.comment)
 	}
-	err = doc.SortBy(options...)
-	if err != nil {
-		return nil, err
-	}
-	d, err := doc.WriteAll()
-	if err != nil {
-		return nil, err
-	}
-	return d, nil
+	return nil
 }
 
 // Marshal returns a WSV encoding of s.
 //
 // Marsal iterates over the elements of s. For each element of s it iterates over the fields of in the s[n].
 //
-// If a field in the s[n] implements [MarshalWSV] it will call the [MarshalWSV.MarshalWSV()]. Otherwise it will
-// use the default implementation.
+// If a field in the s[n] implements [MarshalWSV] it will call the [MarshalWSV.MarshalWSV()]. Otherwise if it implements
+// [encoding.TextMarshaler] it will call MarshalText, except for `time.Time`, and otherwise use the default implementation.
+//
+// The elements of s can also be pointers to structs, e.g. `[]*User`, a nil element is written as a line of nulls.
 //
 // Marshal uses the `wsv` tag of fields within s[n] in following format: `wsv:"[field name][,format:[[string]][,comment]"`
 //
@@ -376,7 +647,16 @@ func MarshalWithOptions[T any](s []T, options ...*internal.SortOption) ([]byte,
 // All exported fields in the struct s[n] will try to marshal unless a specific `wsv` tag with a field name of `-` is provided.
 // If the field name name is expect to literally be `-` there needs to be comma `,` to follow.
 //
-// Supports `string`, `int`, `bool`, `float`, `time.Time`.
+// Supports `string`, `int`, `bool`, `float`, `complex`, `time.Time`.
+//
+// Fields of exported embedded structs, or pointers to structs, without a `wsv` tag are marshalled as columns of s[n].
+// When an embedded pointer is nil each of its columns is null.
+//
+// Fields with the `omitempty` attribute are written as null `-` when they hold the zero value of a `string`, `int`, `float`, `bool` or `time.Time`,
+// for example `wsv:"Notes,omitempty"`. Nil pointers are always written as null.
+//
+// Fields with the `skipempty` attribute are dropped from the output entirely when every value of the column is null or empty,
+// for example `wsv:"Notes,omitempty,skipempty"`. See [Document.DeleteEmptyColumns]
 //
 // Fields with the type of `string` do not support the `format:` attribute in the struct tag and will just be ignored if specified.
 //
@@ -390,7 +670,7 @@ func MarshalWithOptions[T any](s []T, options ...*internal.SortOption) ([]byte,
 //	}
 //
 // Fields with type `bool` can also alter their byte representation with the `format:` attribute in the struct tag.
-// The format is template `true|false` with the left side of the `|` representing the literal value of `true` and the right side representing the literal value of `false`. The default is `True|False`.
+// The format is template `true|false` with the left side of the `|` representing the literal value of `true` and the right side representing the literal value of `false`. The default is `True|False`, or the format given with [WithBoolFormat] of [MarshalWithOptions].
 //
 // Example:
 //
@@ -405,6 +685,9 @@ func MarshalWithOptions[T any](s []T, options ...*internal.SortOption) ([]byte,
 //	  Salary float32 `wsv:"Weekly Salary,format:%.2f"`
 //	}
 //
+// Fields with type `complex` are written with [strconv.FormatComplex] in the shortest form that reads back exactly, e.g. `(1+2i)`,
+// unless the `format:` attribute gives a format of `fmt.Sprintf`.
+//
 // Field with type `time.Time` can alter their byte representation with the `format:` attribute in the struct tag.
 // The format is in the format of `time.Format` and the default is `time.RFC3339`
 // The time can be written a literal string layout `2006-01-02` or using a the following shorthand values:
@@ -2,6 +2,9 @@ package document_test
 
 import (
 	"bytes"
+	"errors"
+	"net"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -485,7 +488,7 @@ func TestMarshalMultipleComments(t *testing.T) {
 	}
 	exp_lines := []string{
 		`"First Name"  "Last Name"`,
-		`Scott         "Eremia-Roden"  #Wrote this program Wrote a test once`,
+		`Scott         Eremia-Roden  #Wrote this program Wrote a test once`,
 		`John          Doe  #Opened a thing Did a thing`,
 		``,
 	}
@@ -579,3 +582,420 @@ func TestMarshalIntPtr(t *testing.T) {
 		t.Error("not the same")
 	}
 }
+
+func TestDecodeLine(t *testing.T) {
+	doc := document.NewDocument()
+	doc.AppendLine(document.Fields("Name", "Age", "Hired")...)
+	doc.AppendLine(document.Fields("Scott", "33", "2024-02-01")...)
+	doc.AppendLine(document.Field("Jane"), document.Null(), document.Field("2020-07-15"))
+
+	type Employee struct {
+		Name  string    `wsv:"Name"`
+		Age   *int      `wsv:"Age"`
+		Hired time.Time `wsv:"Hired,format:dateonly"`
+	}
+	line, err := doc.Line(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := document.DecodeLine[Employee](line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Name != "Jane" || e.Age != nil || e.Hired.Format(time.DateOnly) != "2020-07-15" {
+		t.Errorf("expected Jane with a nil age hired 2020-07-15 but got %+v", e)
+	}
+
+	line, _ = doc.Line(2)
+	e, err = document.DecodeLine[Employee](line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Name != "Scott" || e.Age == nil || *e.Age != 33 {
+		t.Errorf("expected Scott aged 33 but got %+v", e)
+	}
+
+	p, err := document.DecodeLine[*Employee](line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || p.Name != "Scott" {
+		t.Errorf("expected a pointer to Scott but got %+v", p)
+	}
+	if _, err = document.DecodeLine[any](line); err != document.ErrDecodeType {
+		t.Error("expected ErrDecodeType for an interface but got", err)
+	}
+	if _, err = document.DecodeLine[string](line); err != document.ErrDecodeType {
+		t.Error("expected ErrDecodeType for a string but got", err)
+	}
+}
+
+func TestCheckStructMatches(t *testing.T) {
+	doc := document.NewDocument()
+	doc.AppendLine(document.Fields("Name", "Age")...)
+	doc.AppendLine(document.Fields("Scott", "33")...)
+
+	type Person struct {
+		Name  string `wsv:"Name"`
+		Age   int    `wsv:"Age"`
+		Notes string `wsv:"Notes,comment"`
+	}
+	if err := document.CheckStructMatches[Person](doc, document.CheckRequireAllFields()); err != nil {
+		t.Error("expected the struct to match the headers but got", err)
+	}
+	if err := document.CheckStructMatches[*Person](doc); err != nil {
+		t.Error("expected a pointer to the struct to match the headers but got", err)
+	}
+
+	type Employee struct {
+		Name  string `wsv:"Name"`
+		Hired string `wsv:"Hired"`
+	}
+	err := document.CheckStructMatches[Employee](doc)
+	if !errors.Is(err, document.ErrStructMismatch) {
+		t.Fatal("expected ErrStructMismatch but got", err)
+	}
+	if !strings.Contains(err.Error(), "headers without a struct field: Age") || strings.Contains(err.Error(), "Hired") {
+		t.Error("expected only the unmatched header in the error but got", err)
+	}
+	err = document.CheckStructMatches[Employee](doc, document.CheckRequireAllFields())
+	if !errors.Is(err, document.ErrStructMismatch) || !strings.Contains(err.Error(), "struct fields without a header: Hired") {
+		t.Error("expected the field without a header in the error but got", err)
+	}
+	if err := document.CheckStructMatches[string](doc); err == nil {
+		t.Error("expected an error for a type that is not a struct")
+	}
+}
+
+func TestAppendMarshalBatches(t *testing.T) {
+	type Person struct {
+		Name string `wsv:"Name"`
+		Age  *int   `wsv:"Age"`
+	}
+	age := 33
+	doc := document.NewDocument()
+	if err := document.AppendMarshal(doc, []Person{{"Scott", &age}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := document.AppendMarshal(doc, []Person{{"Jane", nil}, {"John", &age}}); err != nil {
+		t.Fatal(err)
+	}
+	type Other struct {
+		Title string `wsv:"Title"`
+	}
+	if err := document.AppendMarshal(doc, []Other{{"x"}}); err != document.ErrHeaderMismatch {
+		t.Error("expected ErrHeaderMismatch but got", err)
+	}
+	if doc.LineCount() != 4 {
+		t.Error("expected 4 lines but got", doc.LineCount())
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Age\n" +
+		"Scott  33\n" +
+		"Jane   -\n" +
+		"John   33\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+type Audit struct {
+	CreatedBy string    `wsv:"Created By"`
+	CreatedAt time.Time `wsv:"Created At,format:20060102"`
+}
+
+func TestMarshalEmbeddedPointer(t *testing.T) {
+	type Account struct {
+		Name string `wsv:"Name"`
+		*Audit
+	}
+	accounts := []Account{
+		{Name: "Scott", Audit: &Audit{CreatedBy: "admin", CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}},
+		{Name: "Jane"},
+	}
+	d, err := document.Marshal(accounts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `Name   "Created By"  "Created At"` + "\n" +
+		"Scott  admin         20240102\n" +
+		"Jane   -             -\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestMarshalNilElement(t *testing.T) {
+	type Person struct {
+		Name  string `wsv:"Name"`
+		Age   int    `wsv:"Age"`
+		Notes string `wsv:",comment"`
+	}
+	people := []*Person{{Name: "Scott", Age: 33, Notes: "first"}, nil, {Name: "Jane", Age: 79}}
+	d, err := document.Marshal(people)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Age\n" +
+		"Scott  33  #first\n" +
+		"-      -\n" +
+		"Jane   79\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+	if _, err := document.Marshal([]*Person{nil}); err != nil {
+		t.Error("expected a nil element alone to marshal the headers and a null line but got", err)
+	}
+}
+
+func TestMarshalOmitEmpty(t *testing.T) {
+	type Record struct {
+		Notes    string        `wsv:"Notes,omitempty"`
+		Count    int           `wsv:"Count,omitempty"`
+		Ratio    float64       `wsv:"Ratio,omitempty"`
+		Active   bool          `wsv:"Active,omitempty"`
+		Seen     time.Time     `wsv:"Seen,format:20060102,omitempty"`
+		Duration time.Duration `wsv:"Duration,omitempty"`
+		Plain    int           `wsv:"Plain"`
+	}
+	records := []Record{
+		{},
+		{"x", 3, 1.5, true, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), time.Second, 0},
+	}
+	d, err := document.Marshal(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Notes  Count  Ratio  Active  Seen      Duration  Plain\n" +
+		"-      -      -      -       -         -         0\n" +
+		"x      3      1.50   True    20240102  1s        0\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestMarshalAnonymousStruct(t *testing.T) {
+	rows := []struct {
+		Name  string  `wsv:"Full Name"`
+		Score float64 `wsv:"Score,format:%.1f"`
+		Note  string  `wsv:",comment"`
+		skip  string
+	}{
+		{"Scott Smith", 9.25, "first", "x"},
+		{"Jane", 10, "", "y"},
+	}
+	d, err := document.Marshal(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := document.Marshal([]int{1, 2}); err != document.ErrUnsupportMarshalType {
+		t.Error("expected ErrUnsupportMarshalType for a slice of non-structs but got", err)
+	}
+	exp := `"Full Name"    Score` + "\n" +
+		`"Scott Smith"  9.2  #first` + "\n" +
+		"Jane           10.0\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestMarshalTextMarshaler(t *testing.T) {
+	type Host struct {
+		Name    string    `wsv:"Name"`
+		IP      net.IP    `wsv:"IP"`
+		Gateway *net.IP   `wsv:"Gateway"`
+		Seen    time.Time `wsv:"Seen,format:20060102"`
+	}
+	hosts := []Host{
+		{"web", net.ParseIP("10.0.0.5"), nil, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	d, err := document.Marshal(hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name  IP        Gateway  Seen\n" +
+		"web   10.0.0.5  -        20240102\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestMarshalSkipEmpty(t *testing.T) {
+	type Record struct {
+		Name     string  `wsv:"Name"`
+		Nickname *string `wsv:"Nickname,skipempty"`
+		Notes    string  `wsv:"Notes,skipempty"`
+		Empty    string  `wsv:"Empty"`
+	}
+	note := "likes tea"
+	records := []Record{{Name: "Scott"}, {Name: "Jane", Notes: note}}
+	d, err := document.Marshal(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `Name   Notes        Empty` + "\n" +
+		`Scott  ""           ""` + "\n" +
+		`Jane   "likes tea"  ""` + "\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestDeleteEmptyColumns(t *testing.T) {
+	doc := document.NewDocument()
+	doc.AppendLine(document.Fields("Name", "A", "B", "C")...)
+	doc.AppendLine(document.Field("Scott"), document.Null(), document.Field(""), document.Field("x"))
+	doc.AppendLine(document.Field("Jane"), document.Null(), document.Null(), document.Null())
+
+	if err := doc.DeleteEmptyColumns(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(doc.Headers(), ",") != "Name,C" {
+		t.Error("expected the empty columns A and B to be removed but got", doc.Headers())
+	}
+}
+
+func TestMarshalWithHeaders(t *testing.T) {
+	type Record struct {
+		Name string `wsv:"name"`
+		Age  int    `wsv:"age"`
+		City string `wsv:"city"`
+	}
+	records := []Record{{Name: "Scott", Age: 33, City: "Boston"}}
+	d, err := document.MarshalWithHeaders(records, map[string]string{"name": "Full Name", "age": "Age"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `"Full Name"  Age  city` + "\n" +
+		`Scott        33   Boston` + "\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+
+	for range 10 {
+		// the display names are the other tag names, so the columns must be resolved before any are renamed
+		d, err = document.MarshalWithHeaders(records, map[string]string{"name": "city", "city": "name"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp = "city   age  name\n" +
+			"Scott  33   Boston\n"
+		if string(d) != exp {
+			t.Fatalf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+		}
+	}
+
+	_, err = document.MarshalWithHeaders(records, map[string]string{"country": "Country"})
+	if !errors.Is(err, document.ErrFieldNameNotFound) {
+		t.Error("expected ErrFieldNameNotFound but got", err)
+	}
+}
+
+func TestMarshalWithFilter(t *testing.T) {
+	type Person struct {
+		Name string `wsv:"Name"`
+		Age  int    `wsv:"Age"`
+	}
+	people := []Person{{"Scott", 33}, {"Jane", 79}, {"John", 9}}
+	d, err := document.MarshalWithOptions(people, document.WithFilter(func(p Person) bool {
+		return p.Age > 10
+	}), document.SortDesc("Age"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Age\n" +
+		"Jane   79\n" +
+		"Scott  33\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+
+	_, err = document.MarshalWithOptions(people, document.WithFilter(func(p Person) bool { return false }))
+	if err != document.ErrNoDataMarshalled {
+		t.Error("expected ErrNoDataMarshalled when every element is filtered but got", err)
+	}
+	_, err = document.MarshalWithOptions(people, document.WithFilter(func(s string) bool { return true }))
+	if err != document.ErrFilterType {
+		t.Error("expected ErrFilterType but got", err)
+	}
+}
+
+func TestMarshalSeq(t *testing.T) {
+	type Person struct {
+		Name string `wsv:"Name"`
+		Age  int    `wsv:"Age"`
+	}
+	people := []Person{{"Scott", 33}, {"Jane", 79}, {"Jo", 9}}
+	var buf bytes.Buffer
+	if err := document.MarshalSeq(slices.Values(people), &buf); err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Age\n" +
+		"Scott  33\n" +
+		"Jane   79\n" +
+		"Jo     9\n"
+	if buf.String() != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, buf.String())
+	}
+
+	buf.Reset()
+	if err := document.MarshalSeq(slices.Values([]*Person{{"Alexander", 5}, nil, {"Jo", 9}}), &buf); err != nil {
+		t.Fatal(err)
+	}
+	exp = "Name       Age\n" +
+		"Alexander  5\n" +
+		"-          -\n" +
+		"Jo         9\n"
+	if buf.String() != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, buf.String())
+	}
+
+	if err := document.MarshalSeq(slices.Values([]Person{}), &buf); err != document.ErrNoDataMarshalled {
+		t.Error("expected ErrNoDataMarshalled for an empty sequence but got", err)
+	}
+	if err := document.MarshalSeq(slices.Values([]string{"a"}), &buf); err != document.ErrUnsupportMarshalType {
+		t.Error("expected ErrUnsupportMarshalType but got", err)
+	}
+}
+
+func TestMarshalWithBoolFormat(t *testing.T) {
+	type User struct {
+		Name   string `wsv:"Name"`
+		Admin  bool   `wsv:"Admin"`
+		Active bool   `wsv:"Active,format:Y|N"`
+	}
+	users := []User{{"Scott", true, true}, {"Jane", false, false}}
+	d, err := document.MarshalWithOptions(users, document.WithBoolFormat("yes|no"), document.Sort("Name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Admin  Active\n" +
+		"Jane   no     N\n" +
+		"Scott  yes    Y\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+}
+
+func TestMarshalWithoutHeaders(t *testing.T) {
+	type User struct {
+		Name  string `wsv:"Name"`
+		Admin bool   `wsv:"Administrator"`
+	}
+	users := []User{{"Scott", true}, {"Jane", false}}
+	d, err := document.MarshalWithOptions(users, document.WithoutHeaders(), document.Sort("Name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Jane   False\n" +
+		"Scott  True\n"
+	if string(d) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(d))
+	}
+	if strings.Contains(string(d), "Name") {
+		t.Error("expected no header line in the output")
+	}
+}
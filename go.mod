@@ -1,5 +1,5 @@
 module github.com/campfhir/wsv
 
-go 1.22.0
+go 1.23.0
 
 retract [v1.0.0, v1.4.4]
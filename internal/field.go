@@ -2,7 +2,9 @@ package internal
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -13,6 +15,22 @@ type Field struct {
 	RowIndex   int
 	FieldName  string
 	IsHeader   bool
+	// byte offsets of the field within the line read, see [Field.Range]
+	start int
+	end   int
+}
+
+// Returns the byte offsets of the field within the line it was read from, `startCol` is the first byte of the field
+// and `endCol` is the byte after it, including the surrounding double quotes of a quoted field.
+// Both are 0 for fields that were not read by a reader.
+func (f *Field) Range() (startCol, endCol int) {
+	return f.start, f.end
+}
+
+// Sets the byte offsets returned by [Field.Range]
+func (f *Field) SetRange(startCol, endCol int) {
+	f.start = startCol
+	f.end = endCol
 }
 
 // Computes the rune length of the serialized value
@@ -23,22 +41,60 @@ func (f *Field) CalculateFieldLength() int {
 
 // Serializes the values of the field
 //
-// - escaping whitespaces, double quoutes, and hyphens from the records value
+// - escaping whitespaces, double quoutes, and leading hyphens from the records value
 //
 // - returns the literal `-` character for null
 //
 // - `""` for an empty string
 func (f *Field) SerializeText() string {
+	return f.SerializeTextWith(SerializeOptions{})
+}
+
+// Serializes the values of the field like [Field.SerializeText] with the options provided
+func (f *Field) SerializeTextWith(opts SerializeOptions) string {
 	if f.IsNull {
-		return "-"
+		return opts.null()
 	}
 
-	return SerializeValue(f.Value)
+	return SerializeValueWith(f.Value, opts)
+}
+
+// When non-null values are wrapped in double quotes
+type Quoting int
+
+const (
+	// Only quote values that need quoting to be parsed
+	QuoteMinimal Quoting = iota
+	// Quote every non-null value
+	QuoteAll
+	// Quote every non-null value that does not parse as a number
+	QuoteNonNumeric
+)
+
+// Options to alter how a value is serialized
+type SerializeOptions struct {
+	// escape double quotes and backslashes within a value with a backslash `\"` rather than doubling the quote `""`
+	BackslashEscape bool
+	// reports whether a rune separates fields so values containing it are quoted, nil uses [IsFieldDelimiter]
+	IsDelimiter func(rune) bool
+	// when to wrap values in double quotes, defaults to [QuoteMinimal]
+	Quoting Quoting
+	// the token written for null values instead of `-`, values equal to the token are quoted
+	Null string
+	// write control characters other than the line feed as `\uHHHH` and backslashes as `\\`, see [EscapeControlChars]
+	EscapeControlChars bool
+}
+
+func (opts SerializeOptions) null() string {
+	if opts.Null != "" {
+		return opts.Null
+	}
+	return "-"
 }
 
 // Maps the `SerializeValue()` function on non-null strings
 //
-// - escaping whitespaces, double quoutes, and hyphens from the records value
+// - escaping whitespaces, double quoutes, and leading hyphens from the records value
 //
 // - `""` for an empty string
 func SerializeValues(s []string) []string {
@@ -49,18 +105,38 @@ func SerializeValues(s []string) []string {
 
 // Seralizes non null values
 //
-// - escaping whitespaces, double quoutes, and hyphens from the records value
+// - escaping whitespaces, double quoutes, and leading hyphens from the records value
 //
 // - `""` for an empty string
 func SerializeValue(v string) string {
+	return SerializeValueWith(v, SerializeOptions{})
+}
+
+// Seralizes non null values like [SerializeValue] with the options provided
+func SerializeValueWith(v string, opts SerializeOptions) string {
 	wrapped := false
+	if opts.EscapeControlChars {
+		v = EscapeControlChars(v)
+	}
 
-	v = strings.ReplaceAll(v, `"`, `""`)
-	if strings.Contains(v, `""`) && !wrapped {
-		wrapped = true
-		v = fmt.Sprintf(`"%s"`, v)
+	if opts.BackslashEscape {
+		if strings.ContainsAny(v, `"\`) {
+			v = strings.ReplaceAll(v, `\`, `\\`)
+			v = strings.ReplaceAll(v, `"`, `\"`)
+			wrapped = true
+			v = fmt.Sprintf(`"%s"`, v)
+		}
+	} else {
+		v = strings.ReplaceAll(v, `"`, `""`)
+		if strings.Contains(v, `""`) && !wrapped {
+			wrapped = true
+			v = fmt.Sprintf(`"%s"`, v)
+		}
 	}
-	if strings.Contains(v, "-") {
+	// a leading `-` would be read as the null specifier, a `-` within the value is read as is.
+	// Another null token is only read as null when it is the whole value
+	null := opts.null()
+	if ((null == "-" && strings.HasPrefix(v, "-")) || v == null) && !wrapped {
 		wrapped = true
 		v = fmt.Sprintf(`"%s"`, v)
 	}
@@ -69,12 +145,73 @@ func SerializeValue(v string) string {
 		wrapped = true
 		v = fmt.Sprintf(`"%s"`, v)
 	}
-	if strings.ContainsFunc(v, IsFieldDelimiter) && !wrapped {
+	isDelimiter := opts.IsDelimiter
+	if isDelimiter == nil {
+		isDelimiter = IsFieldDelimiter
+	}
+	if strings.ContainsFunc(v, isDelimiter) && !wrapped {
 		wrapped = true
 		v = fmt.Sprintf(`"%s"`, v)
 	}
 	if v == "" {
-		v = `""`
+		return `""`
+	}
+	if !wrapped && (opts.Quoting == QuoteAll || (opts.Quoting == QuoteNonNumeric && !IsDecimal(v))) {
+		v = fmt.Sprintf(`"%s"`, v)
 	}
 	return v
 }
+
+// Reports whether r is a control character that is escaped by [EscapeControlChars], the line feed is excluded
+// since it is written as `"/"`
+func IsControlChar(r rune) bool {
+	return r != '\n' && unicode.IsControl(r)
+}
+
+// Escapes the control characters of v as `\uHHHH` and backslashes as `\\` so the value can be aligned and read back
+// with [UnescapeControlChars]
+func EscapeControlChars(v string) string {
+	if !strings.ContainsFunc(v, IsControlChar) && !strings.Contains(v, `\`) {
+		return v
+	}
+	var b strings.Builder
+	for _, r := range v {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case IsControlChar(r):
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Decodes the `\uHHHH` and `\\` escapes of [EscapeControlChars], other backslashes are kept as is
+func UnescapeControlChars(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i+1 >= len(v) {
+			b.WriteByte(v[i])
+			continue
+		}
+		if v[i+1] == '\\' {
+			b.WriteByte('\\')
+			i++
+			continue
+		}
+		if v[i+1] == 'u' && i+6 <= len(v) {
+			if r, err := strconv.ParseUint(v[i+2:i+6], 16, 32); err == nil {
+				b.WriteRune(rune(r))
+				i += 5
+				continue
+			}
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String()
+}
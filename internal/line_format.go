@@ -0,0 +1,24 @@
+package internal
+
+// The original text of a line that was read, used to write the line back with the spacing it was read with
+type LineFormat struct {
+	// the white space before each field
+	Spacing []string
+	// the text of each field as it was read, including any double quotes
+	Raw []string
+	// the text after the last field, the white space before the comment and the comment, or the trailing white space
+	Tail string
+}
+
+// Splits the line into the white space and the text of each field using the byte span of each field, see [Field.Range]
+func NewLineFormat(line string, spans [][2]int) LineFormat {
+	format := LineFormat{Spacing: make([]string, len(spans)), Raw: make([]string, len(spans))}
+	end := 0
+	for i, span := range spans {
+		format.Spacing[i] = line[end:span[0]]
+		format.Raw[i] = line[span[0]:span[1]]
+		end = span[1]
+	}
+	format.Tail = line[end:]
+	return format
+}
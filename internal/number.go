@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parses a number formatted with the decimal and grouping separators provided, e.g. `1.000,50` with a decimal
+// separator of `,` and grouping of `.`, or `1,000.50` with a decimal separator of `.` and grouping of `,`
+//
+// Grouping separators are optional but when present each group after the first must have 3 digits.
+func ParseLocaleNumber(s string, decimal, grouping byte) (float64, bool) {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(s, string(decimal))
+	if hasFraction && (fraction == "" || !isDigits(fraction)) {
+		return 0, false
+	}
+	groups := strings.Split(integer, string(grouping))
+	for i, g := range groups {
+		if !isDigits(g) {
+			return 0, false
+		}
+		if len(groups) > 1 && ((i == 0 && len(g) > 3) || (i > 0 && len(g) != 3)) {
+			return 0, false
+		}
+	}
+	normalized := sign + strings.Join(groups, "")
+	if hasFraction {
+		normalized += "." + fraction
+	}
+	v, err := strconv.ParseFloat(normalized, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// Reports whether s is a decimal number, an optional sign and digits followed by an optional fraction and exponent,
+// such as `-12`, `3.50` or `1e-3`. Unlike [strconv.ParseFloat] the text `NaN`, `Inf` and hexadecimal floats are not numbers.
+func IsDecimal(s string) bool {
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(s), "e")
+	if hasExponent {
+		if strings.HasPrefix(exponent, "-") || strings.HasPrefix(exponent, "+") {
+			exponent = exponent[1:]
+		}
+		if !isDigits(exponent) {
+			return false
+		}
+	}
+	integer, fraction, hasFraction := strings.Cut(mantissa, ".")
+	return isDigits(integer) && (!hasFraction || isDigits(fraction))
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Formats the number `s` with the `fmt` format, such as `%.2f` or `$%08.3f`. Integer verbs `d`, `b`, `o`, `x` and `X`
+// format integers and every other verb formats the number as a float64.
+// Returns false when s is not a number, or the format has an integer verb and s is not an integer.
+func FormatNumber(format, s string) (string, bool) {
+	if isIntegerVerb(formatVerb(format)) {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return s, false
+		}
+		return fmt.Sprintf(format, v), true
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return s, false
+	}
+	return fmt.Sprintf(format, v), true
+}
+
+// returns the verb of the first formatting directive in format, skipping `%%`
+func formatVerb(format string) byte {
+	if verbs := formatVerbs(format); len(verbs) > 0 {
+		return verbs[0]
+	}
+	return 0
+}
+
+// returns the verbs of the formatting directives in format, skipping `%%`, a directive without a verb is returned as 0
+func formatVerbs(format string) []byte {
+	verbs := make([]byte, 0, 1)
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for ; i < len(format); i++ {
+			if c := format[i]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '%' {
+				break
+			}
+		}
+		switch {
+		case i >= len(format):
+			verbs = append(verbs, 0)
+		case format[i] != '%':
+			verbs = append(verbs, format[i])
+		}
+	}
+	return verbs
+}
+
+// Reports whether format has a single formatting directive with a verb [FormatNumber] formats, the integer verbs
+// `d`, `b`, `o`, `x` and `X` or the float verbs `e`, `E`, `f`, `F`, `g` and `G`
+func IsNumberFormat(format string) bool {
+	verbs := formatVerbs(format)
+	return len(verbs) == 1 && verbs[0] != 0 && strings.IndexByte("dboxXeEfFgG", verbs[0]) >= 0
+}
+
+func isIntegerVerb(verb byte) bool {
+	return strings.IndexByte("dboxX", verb) >= 0
+}
@@ -6,26 +6,46 @@ import (
 	"time"
 )
 
-func ParseWSVTag(f reflect.StructField) (key string, isComment bool, format string, literalEmptyField bool) {
-	key = f.Name
-	if tag := f.Tag.Get("wsv"); tag != "" {
-		parts := SplitQuoted(tag)
+// The attributes of a `wsv` struct tag
+type WSVTag struct {
+	// the column name, the name of the struct field when the tag does not have one
+	Key string
+	// the `comment` attribute, the field is the comment of the line instead of a column
+	IsComment bool
+	// the value of the `format:` attribute
+	Format string
+	// the tag is `-` followed by attributes, naming a column `-` instead of skipping the field
+	LiteralEmptyField bool
+	// the `omitempty` attribute, zero values are marshalled as null
+	OmitEmpty bool
+	// the `skipempty` attribute, the column is left out when every value is empty
+	SkipEmpty bool
+}
+
+func ParseWSVTag(f reflect.StructField) WSVTag {
+	tag := WSVTag{Key: f.Name}
+	if wsv := f.Tag.Get("wsv"); wsv != "" {
+		parts := SplitQuoted(wsv)
 		if len(parts) > 0 {
-			key = parts[0]
+			tag.Key = parts[0]
 		}
-		if key == "-" && len(parts) >= 2 {
-			literalEmptyField = true
+		if tag.Key == "-" && len(parts) >= 2 {
+			tag.LiteralEmptyField = true
 		}
 		for _, p := range parts[1:] {
 			switch {
 			case strings.HasPrefix(p, "comment"):
-				isComment = true
+				tag.IsComment = true
 			case strings.HasPrefix(p, "format:"):
-				format = strings.TrimPrefix(p, "format:")
+				tag.Format = strings.TrimPrefix(p, "format:")
+			case p == "omitempty":
+				tag.OmitEmpty = true
+			case p == "skipempty":
+				tag.SkipEmpty = true
 			}
 		}
 	}
-	return
+	return tag
 }
 
 // central lookup
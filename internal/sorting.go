@@ -9,4 +9,7 @@ type SortOption struct {
 	AsTime      bool
 	AsDuration  bool
 	TimeFormat  string
+	// compare null fields as if they held this value, such as "0" to sort nulls as zero with AsNumber,
+	// when empty null fields are sorted last
+	NullAs string
 }
@@ -0,0 +1,408 @@
+package internal
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type unmarshalError struct {
+	field      string
+	format     string
+	comment    bool
+	fieldIndex []int
+	fieldType  string
+	cause      error
+}
+
+// Mirrors the exported reader.UnmarshalWSV interface
+type UnmarshalWSV interface {
+	UnmarshalWSV(value string, format string) error
+}
+
+func (e *unmarshalError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("unmarshal error field: '%s' format: [%s], field index: %d, field type: %s caused by %s", e.field, e.format, e.fieldIndex, e.fieldType, e.cause)
+	}
+	return fmt.Sprintf("unmarshal error field: '%s' format: [%s], field index: %d, field type: %s", e.field, e.format, e.fieldIndex, e.fieldType)
+}
+
+// Options to alter how [UnmarshalRow] converts field values
+type RowOptions struct {
+	// Values accepted as a bool in addition to the `format:` of the field, mapped to the bool they represent
+	BoolTokens map[string]bool
+	// Trim the leading and trailing white space of values set on string and *string fields
+	TrimStrings bool
+}
+
+func (opts RowOptions) str(raw string) string {
+	if opts.TrimStrings {
+		return strings.TrimSpace(raw)
+	}
+	return raw
+}
+
+func (opts RowOptions) parseBool(raw, format string) (bool, error) {
+	if v, ok := opts.BoolTokens[raw]; ok {
+		return v, nil
+	}
+	return ParseBool(raw, format)
+}
+
+// Unmarshals the fields of a row into a new value of the struct type t, fields are matched to the
+// struct fields by the `wsv` tag and the field name
+func UnmarshalRow(fields []Field, t reflect.Type, opts RowOptions) (*reflect.Value, error) {
+	val := reflect.New(t).Elem()
+	if val.Kind() != reflect.Struct {
+		return nil, errors.New("expected a struct to unmarshal to")
+	}
+
+	// Collect all fields indexed by tag name
+	tagLookup := make(map[string]fieldInfo)
+	collectFields(val.Type(), nil, tagLookup)
+
+	for _, field := range fields {
+		fi, ok := tagLookup[field.FieldName]
+		if !ok || !fi.Field.IsExported() {
+			continue
+		}
+
+		tag := ParseWSVTag(fi.Field)
+		key, format := tag.Key, tag.Format
+		if key == "-" && !tag.LiteralEmptyField {
+			continue
+		}
+		if key == "" || key != field.FieldName {
+			continue
+		}
+
+		// a null field does not allocate its embedded pointer, leaving it nil when all of its columns are null
+		sf, ok := fieldByIndexAlloc(val, fi.Index, !field.IsNull)
+		if !ok {
+			continue
+		}
+
+		// Custom Unmarshaler
+		if sf.CanAddr() {
+			if u, ok := sf.Addr().Interface().(UnmarshalWSV); ok {
+				if err := u.UnmarshalWSV(field.Value, format); err != nil {
+					return nil, newUnmarshalError(key, format, fi.Index, sf.Type().String(), err)
+				}
+				continue
+			}
+		}
+
+		// Fallback to encoding.TextUnmarshaler
+		if handled, err := unmarshalText(sf, field); handled {
+			if err != nil {
+				return nil, newUnmarshalError(key, format, fi.Index, sf.Type().String(), err)
+			}
+			continue
+		}
+
+		if err := setValue(sf, field, key, format, fi.Index, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return &val, nil
+}
+
+// Returns the keys of the exported struct fields of t with a `wsv` tag that have no matching column in `columns`,
+// comment fields and fields ignored with `-` are not required
+func MissingColumns(t reflect.Type, columns []string) []string {
+	tagLookup := make(map[string]fieldInfo)
+	collectFields(t, nil, tagLookup)
+	missing := make([]string, 0)
+	for name, fi := range tagLookup {
+		if _, ok := fi.Field.Tag.Lookup("wsv"); !ok || !fi.Field.IsExported() {
+			continue
+		}
+		tag := ParseWSVTag(fi.Field)
+		if tag.IsComment || (tag.Key == "-" && !tag.LiteralEmptyField) || tag.Key != name {
+			continue
+		}
+		if !slices.Contains(columns, tag.Key) {
+			missing = append(missing, tag.Key)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// Returns the columns in `columns` that no exported struct field of t is matched to by [UnmarshalRow], in the order
+// of `columns`
+func UnmatchedColumns(t reflect.Type, columns []string) []string {
+	tagLookup := make(map[string]fieldInfo)
+	collectFields(t, nil, tagLookup)
+	unmatched := make([]string, 0)
+	for _, col := range columns {
+		fi, ok := tagLookup[col]
+		if ok && fi.Field.IsExported() {
+			tag := ParseWSVTag(fi.Field)
+			if tag.Key == col && (tag.Key != "-" || tag.LiteralEmptyField) {
+				continue
+			}
+		}
+		unmatched = append(unmatched, col)
+	}
+	return unmatched
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Sets the field with [encoding.TextUnmarshaler] if the type, or the type it points to, implements it. time.Time is
+// excluded since it is parsed with the `format:` attribute. Null fields leave the value untouched.
+func unmarshalText(sf reflect.Value, field Field) (handled bool, err error) {
+	t := sf.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || !reflect.PointerTo(t).Implements(textUnmarshalerType) || !sf.CanSet() {
+		return false, nil
+	}
+	if field.IsNull {
+		return true, nil
+	}
+	v := reflect.New(t)
+	if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(field.Value)); err != nil {
+		return true, err
+	}
+	if sf.Kind() == reflect.Ptr {
+		sf.Set(v)
+		return true, nil
+	}
+	sf.Set(v.Elem())
+	return true, nil
+}
+
+// setValue assigns a field value according to its kind/pointer type.
+func setValue(sf reflect.Value, field Field, fieldName, format string, idx []int, opts RowOptions) error {
+	// time.Time and time.Duration are matched by type before their struct and int kinds, a null leaves the zero value
+	switch sf.Type() {
+	case timeType:
+		if field.IsNull {
+			return nil
+		}
+		format = ParseStructTagDateFormat(format)
+		t, err := time.Parse(format, field.Value)
+		if err != nil {
+			return newUnmarshalError(fieldName, format, idx, sf.Type().String(), err)
+		}
+		sf.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		if field.IsNull {
+			return nil
+		}
+		d, err := time.ParseDuration(field.Value)
+		if err != nil {
+			return newUnmarshalError(fieldName, format, idx, sf.Type().String(), err)
+		}
+		sf.Set(reflect.ValueOf(d))
+		return nil
+	}
+
+	switch sf.Kind() {
+	case reflect.String:
+		sf.SetString(opts.str(field.Value))
+	case reflect.Bool:
+		format = DefaultIfEmpty(format, "True|False")
+		return setBool(sf, field.Value, fieldName, format, idx, opts)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setInt(sf, field.Value, fieldName, format, idx)
+	case reflect.Float32, reflect.Float64:
+		return setFloat(sf, field.Value, fieldName, format, idx)
+	case reflect.Complex64, reflect.Complex128:
+		if field.IsNull {
+			return nil
+		}
+		return setComplex(sf, field.Value, fieldName, format, idx)
+	case reflect.Ptr:
+		if field.IsNull {
+			return nil
+		}
+		return setPointer(sf, field, fieldName, format, idx, opts)
+	default:
+		return newUnmarshalError(fieldName, format, idx, sf.Type().String(), fmt.Errorf("the type '%s' is not supported to unmarshal without [UnmarshalWSV.UnmarshalWSV]", sf.Type().String()))
+	}
+	return nil
+}
+
+func setBool(sf reflect.Value, raw, field, format string, idx []int, opts RowOptions) error {
+	v, err := opts.parseBool(raw, format)
+	if err != nil {
+		return newUnmarshalError(field, format, idx, sf.Kind().String(), err)
+	}
+	sf.SetBool(v)
+	return nil
+}
+
+var intMap = map[string]int64{
+	"base2":  int64(2),
+	"base8":  int64(8),
+	"base10": int64(10),
+	"base16": int64(16),
+}
+
+func parseInt(raw, format string) (v int64, err error) {
+	base := int64(10)
+	if b, ok := intMap[format]; ok {
+		base = b
+		format = ""
+	}
+	if format != "" {
+		base, err = strconv.ParseInt(format, 10, strconv.IntSize)
+	}
+
+	if err != nil {
+		return
+	}
+	v, err = strconv.ParseInt(raw, int(base), strconv.IntSize)
+	return
+}
+
+func setInt(sf reflect.Value, raw, field string, format string, idx []int) error {
+	v, err := parseInt(raw, format)
+	if err != nil {
+		return newUnmarshalError(field, format, idx, sf.Kind().String(), err)
+	}
+	sf.SetInt(v)
+	return nil
+}
+
+func setFloat(sf reflect.Value, raw, field string, format string, idx []int) error {
+	v, err := strconv.ParseFloat(raw, strconv.IntSize)
+	if err != nil {
+		return newUnmarshalError(field, format, idx, sf.Kind().String(), err)
+	}
+	sf.SetFloat(v)
+	return nil
+}
+
+func setComplex(sf reflect.Value, raw, field string, format string, idx []int) error {
+	v, err := strconv.ParseComplex(raw, sf.Type().Bits())
+	if err != nil {
+		return newUnmarshalError(field, format, idx, sf.Kind().String(), err)
+	}
+	sf.SetComplex(v)
+	return nil
+}
+
+func setPointer(sf reflect.Value, field Field, fieldName, format string, idx []int, opts RowOptions) error {
+	switch sf.Type().Elem().Kind() {
+	case reflect.String:
+		v := opts.str(field.Value)
+		sf.Set(reflect.ValueOf(&v))
+	case reflect.Bool:
+		format = DefaultIfEmpty(format, "True|False")
+		v, err := opts.parseBool(field.Value, format)
+		if err != nil {
+			return newUnmarshalError(fieldName, format, idx, "*bool", err)
+		}
+		sf.Set(reflect.ValueOf(&v))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := parseInt(field.Value, format)
+		if err != nil {
+			return newUnmarshalError(fieldName, format, idx, sf.Type().String(), err)
+		}
+		sf.Set(reflect.New(sf.Type().Elem()))
+		sf.Elem().SetInt(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(field.Value, 64)
+		if err != nil {
+			return newUnmarshalError(fieldName, format, idx, sf.Type().String(), err)
+		}
+		sf.Set(reflect.New(sf.Type().Elem()))
+		sf.Elem().SetFloat(v)
+	case reflect.Complex64, reflect.Complex128:
+		sf.Set(reflect.New(sf.Type().Elem()))
+		return setComplex(sf.Elem(), field.Value, fieldName, format, idx)
+	default:
+		// Handle special cases like *time.Time
+		if sf.Type() == reflect.TypeOf(&time.Time{}) {
+			format = ParseStructTagDateFormat(format)
+			v, err := time.Parse(format, field.Value)
+			if err != nil {
+				return newUnmarshalError(fieldName, format, idx, "*time.Time", err)
+			}
+			sf.Set(reflect.ValueOf(&v))
+			return nil
+		}
+		return newUnmarshalError(fieldName, format, idx, sf.Type().String(), nil)
+	}
+	return nil
+}
+
+func newUnmarshalError(field string, format string, idx []int, typ string, cause error) error {
+	return &unmarshalError{
+		field:      field,
+		format:     format,
+		fieldIndex: idx,
+		fieldType:  typ,
+		cause:      cause,
+	}
+}
+
+type fieldInfo struct {
+	Index []int
+	Field reflect.StructField
+}
+
+// Returns the nested field at the index like [reflect.Value.FieldByIndex], allocating nil embedded pointers to structs
+// along the way when `alloc` is true. Returns false if an embedded pointer is nil and is not allocated or cannot be set,
+// such as an unexported embedded type.
+func fieldByIndexAlloc(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return v, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// collectFields flattens all fields (including embedded) with tag info
+func collectFields(t reflect.Type, parentIndex []int, tags map[string]fieldInfo) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		idx := append(slices.Clone(parentIndex), i)
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			// recurse into embedded struct
+			collectFields(f.Type, idx, tags)
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct {
+			// recurse into embedded pointer to a struct, allocated when one of its fields is set
+			collectFields(f.Type.Elem(), idx, tags)
+			continue
+		}
+
+		tag, _ := f.Tag.Lookup("wsv")
+		if tag == "" {
+			tag = f.Name
+		}
+		keys := SplitQuoted(tag)
+		if len(keys) == 0 || keys[0] == "" {
+			continue
+		}
+
+		tags[keys[0]] = fieldInfo{Index: idx, Field: f}
+	}
+}
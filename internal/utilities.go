@@ -35,6 +35,11 @@ const (
 	CharIdeographicSpace        = 0x3000
 )
 
+// The name of a column without a header, `col1` for the first column
+func PositionalColumnName(index int) string {
+	return fmt.Sprintf("col%d", index+1)
+}
+
 func RuneToBytes(rs []rune) []byte {
 	b := []byte{}
 	for _, r := range rs {
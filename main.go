@@ -1,9 +1,11 @@
 package main
 
 import (
+	"cmp"
 	"flag"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -22,7 +24,10 @@ func main() {
 		verify      bool
 		tabular     bool
 		sorting     string
+		selecting   string
+		filters     rowFilters
 		showVersion bool
+		pad         int
 	)
 	flag.StringVar(&input, "input", "-", "input file, use `-` for stdin (default stdin)")
 	flag.StringVar(&input, "i", "-", "input file, use `-` for stdin (default stdin)")
@@ -32,10 +37,13 @@ func main() {
 	flag.StringVar(&output, "o", "-", "output file, use `-` for stdout (default stdout)")
 	flag.StringVar(&sorting, "sort", "", "sort by column(s) seperated by `,` will be sorted in the order provided, can use `::` modifier followed by asc or desc to specify direction (defaults asc)")
 	flag.StringVar(&sorting, "s", "", "sort by column(s) seperated by `,` will be sorted in the order provided, can use `::` modifier followed by asc or desc to specify direction (defaults asc)")
+	flag.Var(&filters, "filter", "keep only the rows where `column<op>value` is true, <op> is one of =, !=, <, <=, >, >=, values are compared as numbers when both parse as numbers, can be repeated to require every filter")
+	flag.StringVar(&selecting, "select", "", "keep only the column(s) seperated by `,` in the order provided")
 	flag.BoolVar(&tabular, "tabular", true, "specify if a document is tabular or not")
 	flag.BoolVar(&verify, "verify", false, "verify that input is valid wsv")
 	flag.BoolVar(&verify, "v", false, "verify that input is valid wsv")
 	flag.BoolVar(&showVersion, "version", false, "print the version")
+	flag.IntVar(&pad, "pad", 2, "number of spaces between columns, must be at least 1")
 	flag.Parse()
 
 	if showVersion {
@@ -44,6 +52,12 @@ func main() {
 		return
 	}
 
+	if pad < 1 {
+		fmt.Fprintf(os.Stderr, "the padding [%d] is not valid, it must be at least 1\n", pad)
+		os.Exit(1)
+		return
+	}
+
 	if input != "-" {
 		inputPath, err := internal.Resolve(input)
 		if err != nil {
@@ -109,10 +123,38 @@ func main() {
 		os.Exit(2)
 		return
 	}
+	if len(filters) > 0 {
+		for _, f := range filters {
+			if !slices.Contains(doc.Headers(), f.column) {
+				fmt.Fprintf(os.Stderr, "the column [%s] in the filter [%s] was not found\n", f.column, f)
+				os.Exit(1)
+				return
+			}
+		}
+		doc, err = doc.Filter(filters.match)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to filter the document due to %s\n", err)
+			os.Exit(1)
+			return
+		}
+	}
+	if err := doc.SetPadding([]rune(strings.Repeat(" ", pad))); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to set the padding due to %s\n", err)
+		os.Exit(1)
+		return
+	}
+	if selecting != "" {
+		columns := internal.SplitQuoted(selecting)
+		if err := doc.SelectColumns(columns...); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to select the columns [%s] due to %s\n", selecting, err)
+			os.Exit(1)
+			return
+		}
+	}
 	if sorting != "" {
 
 		columnsModifiers := internal.SplitQuoted(sorting)
-		sortingModifier := internal.Map(columnsModifiers, func(e string, _ int, _ []string) *internal.SortOption {
+		sortingModifier := internal.Map(columnsModifiers, func(e string, _ int, _ []string) *document.SortOption {
 			if e == "" {
 				return nil
 			}
@@ -231,3 +273,89 @@ func main() {
 	}
 
 }
+
+// A comparison of the value of a column parsed from the `-filter` flag
+//
+// The grammar is `column<op>value` where <op> is one of `=`, `!=`, `<`, `<=`, `>`, `>=`, the column is everything
+// before the first operator and the value everything after it, for example `Age>=30` or `Name!=Scott`.
+// When both the field and the value parse as numbers they are compared as numbers, otherwise as strings.
+// Null fields never match.
+type rowFilter struct {
+	column   string
+	operator string
+	value    string
+}
+
+func (f rowFilter) String() string {
+	return f.column + f.operator + f.value
+}
+
+func parseRowFilter(s string) (rowFilter, error) {
+	i := strings.IndexAny(s, "=!<>")
+	if i <= 0 {
+		return rowFilter{}, fmt.Errorf("the filter [%s] must be in the form column<op>value", s)
+	}
+	op := s[i : i+1]
+	if strings.HasPrefix(s[i+1:], "=") {
+		op = s[i : i+2]
+	}
+	if op == "!" {
+		return rowFilter{}, fmt.Errorf("the operator in the filter [%s] is not valid, can only be =, !=, <, <=, >, >=", s)
+	}
+	return rowFilter{column: s[:i], operator: op, value: s[i+len(op):]}, nil
+}
+
+func (f rowFilter) match(line document.Line) bool {
+	field, err := line.FieldByName(f.column)
+	if err != nil || field.IsNull {
+		return false
+	}
+	var c int
+	a, aErr := strconv.ParseFloat(field.Value, 64)
+	b, bErr := strconv.ParseFloat(f.value, 64)
+	if aErr == nil && bErr == nil {
+		c = cmp.Compare(a, b)
+	} else {
+		c = strings.Compare(field.Value, f.value)
+	}
+	switch f.operator {
+	case "=", "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// The `-filter` flags, a row is kept when it matches every filter
+type rowFilters []rowFilter
+
+func (fs *rowFilters) String() string {
+	return fmt.Sprint([]rowFilter(*fs))
+}
+
+func (fs *rowFilters) Set(s string) error {
+	f, err := parseRowFilter(s)
+	if err != nil {
+		return err
+	}
+	*fs = append(*fs, f)
+	return nil
+}
+
+func (fs rowFilters) match(line document.Line) bool {
+	for _, f := range fs {
+		if !f.match(line) {
+			return false
+		}
+	}
+	return true
+}
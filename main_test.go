@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// re-executing the test binary with this set runs the cli instead of the tests
+	if os.Getenv("WSV_RUN_CLI") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// Runs the cli with the args and stdin provided, returning stdout, stderr and the exit code
+func runCLI(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "WSV_RUN_CLI=1")
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), 0
+}
+
+func TestPadFlag(t *testing.T) {
+	input := "Name Age\nScott 33\n"
+	out, stderr, code := runCLI(t, input)
+	if code != 0 {
+		t.Fatal(stderr)
+	}
+	if out != "Name   Age\nScott  33\n" {
+		t.Errorf("expected the default padding of 2 spaces but got\n%s", out)
+	}
+
+	out, stderr, code = runCLI(t, input, "-pad", "4")
+	if code != 0 {
+		t.Fatal(stderr)
+	}
+	if out != "Name     Age\nScott    33\n" {
+		t.Errorf("expected a padding of 4 spaces but got\n%s", out)
+	}
+
+	_, stderr, code = runCLI(t, input, "-pad", "0")
+	if code == 0 {
+		t.Error("expected a non-zero exit code for a padding of 0")
+	}
+	if !strings.Contains(stderr, "padding") {
+		t.Error("expected an error about the padding but got", stderr)
+	}
+}
+
+func TestSelectFlag(t *testing.T) {
+	input := "Name Age City\nScott 33 Boston #reviewed\nJane 79 Denver\n"
+	out, stderr, code := runCLI(t, input, "-select", "City,Name")
+	if code != 0 {
+		t.Fatal(stderr)
+	}
+	exp := "City    Name\n" +
+		"Boston  Scott  #reviewed\n" +
+		"Denver  Jane\n"
+	if out != exp {
+		t.Errorf("expected only the selected columns in the given order but got\n%s", out)
+	}
+
+	_, stderr, code = runCLI(t, input, "-select", "Name,Country")
+	if code == 0 {
+		t.Error("expected a non-zero exit code for an unknown column")
+	}
+	if !strings.Contains(stderr, "Country") {
+		t.Error("expected an error naming the unknown column but got", stderr)
+	}
+}
+
+func TestFilterFlag(t *testing.T) {
+	input := "Name Age\nScott 33\nJane 79\nJohn 9\nEva -\n"
+	out, stderr, code := runCLI(t, input, "-filter", "Age>10", "-filter", "Name!=Jane")
+	if code != 0 {
+		t.Fatal(stderr)
+	}
+	if out != "Name   Age\nScott  33\n" {
+		t.Errorf("expected only the rows matching every filter but got\n%s", out)
+	}
+
+	out, stderr, code = runCLI(t, input, "-filter", "Name<K")
+	if code != 0 {
+		t.Fatal(stderr)
+	}
+	if out != "Name  Age\nJane  79\nJohn  9\nEva   -\n" {
+		t.Errorf("expected a string comparison for non-numeric values but got\n%s", out)
+	}
+
+	_, stderr, code = runCLI(t, input, "-filter", "Country=US")
+	if code == 0 || !strings.Contains(stderr, "Country") {
+		t.Error("expected an error for an unknown column but got", stderr)
+	}
+
+	_, _, code = runCLI(t, input, "-filter", "Age")
+	if code == 0 {
+		t.Error("expected a non-zero exit code for a filter without an operator")
+	}
+}
@@ -0,0 +1,100 @@
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Checks the start of the stream for a byte order mark, a UTF-8 BOM is discarded and a UTF-16 BOM
+// causes the rest of the stream to be decoded into UTF-8
+func (r *Reader) detectBOM() {
+	r.bomChecked = true
+	b, _ := r.br.Peek(len(bomUTF8))
+	switch {
+	case bytes.HasPrefix(b, bomUTF8):
+		r.br.Discard(len(bomUTF8))
+		r.offset += int64(len(bomUTF8))
+	case bytes.HasPrefix(b, bomUTF16LE):
+		r.br.Discard(len(bomUTF16LE))
+		r.br = bufio.NewReader(&utf16Reader{src: r.br, order: binary.LittleEndian})
+		r.transcoded = true
+	case bytes.HasPrefix(b, bomUTF16BE):
+		r.br.Discard(len(bomUTF16BE))
+		r.br = bufio.NewReader(&utf16Reader{src: r.br, order: binary.BigEndian})
+		r.transcoded = true
+	}
+}
+
+// Decodes a UTF-16 stream into UTF-8, the buffers are reused between reads
+type utf16Reader struct {
+	src   io.Reader
+	order binary.ByteOrder
+	// the bytes read from src
+	chunk []byte
+	// decoded bytes, the ones before pos have been read
+	buf []byte
+	pos int
+	// bytes that could not be decoded yet, an odd byte or a high surrogate waiting for its pair
+	raw []byte
+	err error
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	if u.chunk == nil {
+		u.chunk = make([]byte, 4096)
+	}
+	for u.pos == len(u.buf) {
+		if u.err != nil {
+			return 0, u.err
+		}
+		u.buf, u.pos = u.buf[:0], 0
+		n, err := u.src.Read(u.chunk)
+		u.raw = append(u.raw, u.chunk[:n]...)
+		u.err = err
+		u.decode()
+	}
+	n := copy(p, u.buf[u.pos:])
+	u.pos += n
+	return n, nil
+}
+
+// Decodes the code units of raw into buf the same as [utf16.Decode], an unpaired surrogate is decoded as [utf8.RuneError]
+func (u *utf16Reader) decode() {
+	units := len(u.raw) / 2
+	if u.err == nil && units > 0 {
+		// hold on to a trailing high surrogate until the low surrogate is read
+		if last := u.order.Uint16(u.raw[2*(units-1):]); last >= 0xD800 && last < 0xDC00 {
+			units--
+		}
+	}
+	for i := 0; i < units; i++ {
+		rn := rune(u.order.Uint16(u.raw[2*i:]))
+		if utf16.IsSurrogate(rn) && i+1 < units {
+			if pair := utf16.DecodeRune(rn, rune(u.order.Uint16(u.raw[2*(i+1):]))); pair != utf8.RuneError {
+				u.buf = utf8.AppendRune(u.buf, pair)
+				i++
+				continue
+			}
+		}
+		if utf16.IsSurrogate(rn) {
+			rn = utf8.RuneError
+		}
+		u.buf = utf8.AppendRune(u.buf, rn)
+	}
+	// keep the bytes that were not decoded at the start of raw
+	u.raw = append(u.raw[:0], u.raw[2*units:]...)
+	if u.err != nil && len(u.raw) > 0 {
+		u.buf = utf8.AppendRune(u.buf, utf8.RuneError)
+		u.raw = u.raw[:0]
+	}
+}
@@ -0,0 +1,181 @@
+package reader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+)
+
+var (
+	ErrNotSeekable         = errors.New("an index needs a seekable source that is not decoded from UTF-16")
+	ErrIndexAfterRead      = errors.New("an index must be built before any lines are read")
+	ErrInvalidIndex        = errors.New("invalid index")
+	ErrLineNotIndexed      = errors.New("line is not in the index")
+	indexMagic             = []byte("WSVI")
+	indexVersion      byte = 1
+)
+
+// The byte offset of every line in a document along with the header, so a line can be read
+// without parsing the lines before it. See [Reader.BuildIndex]
+type Index struct {
+	// the 1-indexed line number of the header line, 0 when the document does not include a header
+	HeaderLine int
+	Headers    []string
+	// the byte offset of the start of each line, line 1 is at Offsets[0]
+	Offsets []int64
+}
+
+// Reads the whole document recording the byte offset of every line, the index can be saved with [Index.WriteTo] and
+// used by [Index.ReadLine] to read a line directly. The lines read are available the same as after [Reader.ReadAll].
+//
+// The reader needs to be created with an [io.Seeker], such as an *os.File, and no lines can have been read yet.
+// Returns ErrNotSeekable otherwise or the first error reading a line.
+func (r *Reader) BuildIndex() (*Index, error) {
+	if _, ok := r.src.(io.Seeker); !ok {
+		return nil, ErrNotSeekable
+	}
+	if r.numLine > 0 || r.ended {
+		return nil, ErrIndexAfterRead
+	}
+	r.indexing = true
+	defer func() { r.indexing = false }()
+	for {
+		_, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.transcoded {
+		return nil, ErrNotSeekable
+	}
+	idx := &Index{Headers: slices.Clone(r.headers), Offsets: slices.Clone(r.lineOffsets)}
+	if r.readsHeader() {
+		idx.HeaderLine = r.firstDataRow
+	}
+	return idx, nil
+}
+
+// Seeks `src` to the 1-indexed line `n` and reads it with the default [Reader] options, the fields are named
+// with the headers of the index.
+//
+// Returns ErrLineNotIndexed if the line is not in the index.
+func (idx *Index) ReadLine(src io.ReadSeeker, n int) (Line, error) {
+	if n < 1 || len(idx.Offsets) < n {
+		return nil, ErrLineNotIndexed
+	}
+	if _, err := src.Seek(idx.Offsets[n-1], io.SeekStart); err != nil {
+		return nil, err
+	}
+	r := NewReader(src)
+	r.bomChecked = true
+	r.numLine = n - 1
+	switch {
+	case idx.HeaderLine == 0:
+		// a headerless document, possibly with names from [Reader.SetColumnNames]
+		r.IncludesHeader = false
+		r.headers = slices.Clone(idx.Headers)
+		r.firstDataRow = -1
+	case idx.HeaderLine != n:
+		r.headers = slices.Clone(idx.Headers)
+		r.firstDataRow = idx.HeaderLine
+	}
+	return r.Read()
+}
+
+// Writes the index to w in a gzip compressed binary format that can be read back with [ReadIndex]
+func (idx *Index) WriteTo(w io.Writer) (int64, error) {
+	payload := make([]byte, 0, 8+len(idx.Offsets)*2)
+	payload = binary.AppendUvarint(payload, uint64(idx.HeaderLine))
+	payload = binary.AppendUvarint(payload, uint64(len(idx.Headers)))
+	for _, h := range idx.Headers {
+		payload = binary.AppendUvarint(payload, uint64(len(h)))
+		payload = append(payload, h...)
+	}
+	payload = binary.AppendUvarint(payload, uint64(len(idx.Offsets)))
+	prev := int64(0)
+	for _, o := range idx.Offsets {
+		// offsets only increase so store the difference to the previous one
+		payload = binary.AppendUvarint(payload, uint64(o-prev))
+		prev = o
+	}
+
+	var buf bytes.Buffer
+	buf.Write(indexMagic)
+	buf.WriteByte(indexVersion)
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+}
+
+// Reads an index written by [Index.WriteTo], returns ErrInvalidIndex if the data is not an index
+func ReadIndex(r io.Reader) (*Index, error) {
+	header := make([]byte, len(indexMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrInvalidIndex
+	}
+	if !bytes.Equal(header[:len(indexMagic)], indexMagic) || header[len(indexMagic)] != indexVersion {
+		return nil, ErrInvalidIndex
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrInvalidIndex
+	}
+	payload, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, ErrInvalidIndex
+	}
+
+	next := func() (uint64, bool) {
+		v, n := binary.Uvarint(payload)
+		if n <= 0 {
+			return 0, false
+		}
+		payload = payload[n:]
+		return v, true
+	}
+	idx := &Index{}
+	headerLine, ok := next()
+	if !ok {
+		return nil, ErrInvalidIndex
+	}
+	idx.HeaderLine = int(headerLine)
+	count, ok := next()
+	if !ok || count > uint64(len(payload)) {
+		return nil, ErrInvalidIndex
+	}
+	idx.Headers = make([]string, 0, count)
+	for range count {
+		l, ok := next()
+		if !ok || l > uint64(len(payload)) {
+			return nil, ErrInvalidIndex
+		}
+		idx.Headers = append(idx.Headers, string(payload[:l]))
+		payload = payload[l:]
+	}
+	count, ok = next()
+	if !ok || count > uint64(len(payload)) {
+		return nil, ErrInvalidIndex
+	}
+	idx.Offsets = make([]int64, 0, count)
+	prev := int64(0)
+	for range count {
+		d, ok := next()
+		if !ok {
+			return nil, ErrInvalidIndex
+		}
+		prev += int64(d)
+		idx.Offsets = append(idx.Offsets, prev)
+	}
+	return idx, nil
+}
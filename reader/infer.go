@@ -0,0 +1,77 @@
+package reader
+
+import (
+	"github.com/campfhir/wsv/internal"
+)
+
+// The type of values found in a column
+type ColumnType int
+
+const (
+	// every value in the column is null
+	ColumnTypeUnknown ColumnType = iota
+	ColumnTypeString
+	// every non-null value in the column is a number in the reader's [NumberLocale]
+	ColumnTypeNumber
+)
+
+func (c ColumnType) String() string {
+	switch c {
+	case ColumnTypeString:
+		return "string"
+	case ColumnTypeNumber:
+		return "number"
+	}
+	return "unknown"
+}
+
+// Determines the decimal and grouping separators of numbers
+type NumberLocale int
+
+const (
+	// numbers formatted as `1,000.50`
+	NumberLocaleUS NumberLocale = iota
+	// numbers formatted as `1.000,50`
+	NumberLocaleEuropean
+)
+
+func (l NumberLocale) separators() (decimal byte, grouping byte) {
+	if l == NumberLocaleEuropean {
+		return ',', '.'
+	}
+	return '.', ','
+}
+
+// Returns true if the value is a number formatted in the locale
+func (l NumberLocale) IsNumber(v string) bool {
+	decimal, grouping := l.separators()
+	_, ok := internal.ParseLocaleNumber(v, decimal, grouping)
+	return ok
+}
+
+// Infers the type of each column from the data lines read so far, call after [Reader.ReadAll] to consider the whole document.
+//
+// Numbers are recognized using the decimal and grouping separators of `r.NumberLocale`.
+func (r *Reader) InferColumnTypes() []ColumnType {
+	count := len(r.headers)
+	for _, line := range r.lines {
+		count = max(count, line.FieldCount())
+	}
+	types := make([]ColumnType, count)
+	for _, line := range r.lines {
+		if line.IsHeaderLine() {
+			continue
+		}
+		for i, field := range line.Fields() {
+			if field.IsNull || types[i] == ColumnTypeString {
+				continue
+			}
+			if r.NumberLocale.IsNumber(field.Value) {
+				types[i] = ColumnTypeNumber
+				continue
+			}
+			types[i] = ColumnTypeString
+		}
+	}
+	return types
+}
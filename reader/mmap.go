@@ -0,0 +1,20 @@
+package reader
+
+// Creates a reader over the file at `path` mapped into memory, for reading large files without copying them into
+// the heap, the pages of the file are loaded as the reader reaches them. On platforms without memory mapping the file
+// is read as a stream instead.
+//
+// The returned func unmaps the file and needs to be called once reading is done, the reader must not be used after.
+//
+//	r, unmap, err := reader.NewReaderFromMmap("large.wsv")
+//	if err != nil {
+//	  return err
+//	}
+//	defer unmap()
+func NewReaderFromMmap(path string) (*Reader, func() error, error) {
+	src, unmap, err := openMapped(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewReader(src), unmap, nil
+}
@@ -0,0 +1,17 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package reader
+
+import (
+	"io"
+	"os"
+)
+
+// memory mapping is not supported, the file is read as a stream and closed by the returned func
+func openMapped(path string) (io.Reader, func() error, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file.Close, nil
+}
@@ -0,0 +1,45 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+)
+
+// maps the file read only, the mapping stays valid once the file is closed
+func openMapped(path string) (io.Reader, func() error, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		// an empty file cannot be mapped
+		return bytes.NewReader(nil), func() error { return nil }, nil
+	}
+	if int64(int(size)) != size {
+		return nil, nil, &os.PathError{Op: "mmap", Path: path, Err: fmt.Errorf("file of %d bytes is too large to map", size)}
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, &os.PathError{Op: "mmap", Path: path, Err: err}
+	}
+	unmap := func() error {
+		if data == nil {
+			return nil
+		}
+		err := syscall.Munmap(data)
+		data = nil
+		return err
+	}
+	return bytes.NewReader(data), unmap, nil
+}
@@ -0,0 +1,280 @@
+package reader
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	utils "github.com/campfhir/wsv/internal"
+)
+
+// The pointer based lookback parseLine used before it was rewritten to index the line, kept to check the two agree
+func parseLinePointers(n int, line []byte, isDelimiter func(rune) bool) ([]lineField, error) {
+	var b1 *byte = nil
+	var b2 *byte = nil
+	var b3 *byte = nil
+	var b4 *byte = nil
+
+	doubleQuoted := false
+
+	isNull := false
+	startDoubleQuote := 0
+	escapedDoubleQuote := 0
+	data := []byte{}
+	str := make([]lineField, 0)
+	// trim the trailing white space from the line
+	// line = bytes.TrimRightFunc(line, utils.IsFieldDelimiter)
+lineLoop:
+	for i, b0 := range line {
+		if b4 != nil {
+			b4 = b3
+			b3 = b2
+			b2 = b1
+			b1 = &b0
+		}
+		if b4 == nil && b3 != nil {
+			b4 = b3
+			b3 = b2
+			b2 = b1
+			b1 = &b0
+		}
+		if b3 == nil && b2 != nil {
+			b3 = b2
+			b2 = b1
+			b1 = &b0
+		}
+		if b2 == nil && b1 != nil {
+			b2 = b1
+			b1 = &b0
+		}
+		if b1 == nil {
+			b1 = &b0
+		}
+		r := rune(b0)
+
+		switch r {
+		case '\n':
+			break lineLoop
+		case '#':
+			if !doubleQuoted {
+				if len(line[i:]) < 2 {
+					break lineLoop
+				}
+				data = append(data, line[i+1:]...)
+				// since we are copying to the end of line we should remove the suffix of the line feed
+				data = bytes.TrimSuffix(data, []byte{'\n'})
+				str = append(str, lineField{IsComment: true, Value: string(data), IsNull: isNull, Col: i, RawLine: line})
+				// s = ""
+				data = []byte{}
+				break lineLoop
+			}
+			data = append(data, byte(r))
+			continue
+		case '"':
+			if bytesToString(b3, b2, b1) == `"/"` {
+				data = append(bytes.TrimSuffix(data, []byte{'/'}), byte('\n'))
+				continue
+			}
+
+			if (b2 == nil || isDelimiter(rune(*b2))) && !doubleQuoted {
+				doubleQuoted = true
+				startDoubleQuote = i
+				continue
+			}
+
+			if (b3 == nil || isDelimiter(rune(*b3))) && b2 != nil && rune(*b2) == '"' && (len(line)-1 == i || (len(line)-1 > i && isDelimiter(nextRune(line[i+1:])))) {
+				data = []byte{}
+				str = append(str, lineField{IsComment: false, Value: string(data), IsNull: isNull, Col: i, RawLine: line})
+				doubleQuoted = false
+				continue
+			}
+
+			if b2 != nil && rune(*b2) == '"' && (b3 == nil || rune(*b3) != '"') && !(len(line)-1 > i+1 && isDelimiter(nextRune(line[i+1:])) && b3 != nil && rune(*b3) == '/') && !(len(line)-1 > i+2 && nextRune(line[i+1:]) == '/' && nextRune(line[i+2:]) == '"') {
+				data = append(data, byte('"'))
+				escapedDoubleQuote = i
+				continue
+			}
+
+			if doubleQuoted && (len(line)-1 == i || (len(line)-1 > i && isDelimiter(nextRune(line[i+1:])))) && (b2 == nil || rune(*b2) != '"' || i > escapedDoubleQuote) {
+				doubleQuoted = false
+
+			}
+
+		case '-':
+			if r == '-' && (b2 == nil || isDelimiter(rune(*b2))) && !doubleQuoted {
+				isNull = true
+			}
+			fallthrough
+		default:
+
+			if bytesToString(b3, b2, b1) == `"/"` {
+				data = append(bytes.TrimSuffix(data, []byte{'/'}), byte('\n'))
+			}
+			if isNull && (len(line)-1 == i) {
+				str = append(str, lineField{IsComment: false, Value: "", IsNull: isNull, Col: i, RawLine: line})
+				break lineLoop
+			}
+			// currently flagged as null but has more characters left to parse and
+			if isNull && len(line)-1 > i && bytes.IndexFunc(line[i:], isDelimiter) != 1 {
+				// the next immediate character is a white space
+				if b2 != nil && rune(*b2) == '-' && bytes.IndexFunc([]byte{*b1}, isDelimiter) == 0 {
+					data = []byte{}
+				} else {
+					// and is not surround by double quotes we have an invalid
+					return str, &parseError{FieldPosition: i, Err: ErrInvalidNull, ColumnPosition: i, Line: n, RawLine: line}
+				}
+
+			}
+
+			isDelim := isDelimiter(r)
+			if isDelim && (!doubleQuoted) {
+				if len(data) == 0 && !isNull {
+					continue
+				}
+				if string(data) == `"` {
+					return str, &parseError{Line: n, Err: ErrBareQuote, FieldPosition: i, RawLine: line}
+				}
+				str = append(str, lineField{IsComment: false, Value: string(data), IsNull: isNull, Col: i, RawLine: line})
+				isNull = false
+				data = []byte{}
+				continue
+			}
+			if isNull && r == '-' {
+				// since we identified the field as null and
+				continue
+			}
+			data = append(data, byte(r))
+			continue
+		}
+	}
+	if doubleQuoted {
+		// the following string value could not be parsed correctly
+		return str, &parseError{FieldPosition: startDoubleQuote, Err: ErrBareQuote, Line: n, RawLine: line, ColumnPosition: startDoubleQuote}
+	}
+	if len(data) > 0 {
+		if string(data) == `"` {
+			return str, &parseError{Line: n, Err: ErrBareQuote, FieldPosition: startDoubleQuote, RawLine: line, ColumnPosition: startDoubleQuote}
+		}
+		str = append(str, lineField{IsComment: false, Value: string(data), IsNull: isNull, RawLine: line})
+
+	}
+	return str, nil
+}
+
+func bytesToString(s ...*byte) string {
+	str := ""
+	for _, b := range s {
+		if b == nil {
+			continue
+		}
+		str = str + string(*b)
+	}
+	return str
+}
+
+func FuzzParseLineLookback(f *testing.F) {
+	for _, seed := range []string{
+		`a b c`,
+		`"a b" - "" c # comment`,
+		`"line"/"feed" "say ""hi""" x`,
+		`-- - -a "-" "`,
+		`"/" "a"/"" ""/"b"`,
+		"tab\tseparated \t\"x\"\n",
+		"\"\"\n\"",
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, line []byte) {
+		if slices.Contains(literalQuoteSlashQuote(line, utils.IsFieldDelimiter), true) {
+			// the pointer based parser read the literal text `""/""` and `"/"""` as new lines
+			t.Skip()
+		}
+		if i := bytes.IndexByte(line, '\n'); i >= 0 && endsQuoted(line[:i], false, utils.IsFieldDelimiter) {
+			// the pointer based parser stopped at a line feed within a double quoted field
+			t.Skip()
+		}
+		want, wantErr := parseLinePointers(1, line, utils.IsFieldDelimiter)
+		got, gotErr := parseLine(1, line)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parsing %q expected %+v but got %+v", line, want, got)
+		}
+		if !reflect.DeepEqual(gotErr, wantErr) {
+			t.Errorf("parsing %q expected error %v but got %v", line, wantErr, gotErr)
+		}
+	})
+}
+
+var benchmarkLine = []byte(`"Scott Eremia-Roden"  33  "line one"/"line two"  -  "say ""hi"""  ""  # a trailing comment`)
+
+func BenchmarkParseLine(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		parseLine(1, benchmarkLine)
+	}
+}
+
+func BenchmarkParseLinePointers(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		parseLinePointers(1, benchmarkLine, utils.IsFieldDelimiter)
+	}
+}
+
+// returns true for the error types returned for a line that could not be parsed
+func isParseError(err error) bool {
+	var pe *parseError
+	var fe *invalidFieldCountError
+	return errors.As(err, &pe) || errors.As(err, &fe)
+}
+
+func FuzzParseLine(f *testing.F) {
+	for _, seed := range []string{
+		`a b c`,
+		`"a b" - "" c # comment`,
+		`"line"/"feed" "say ""hi""" x`,
+		`-- - -a "-" "`,
+		`"/" "a"/"" ""/"b"`,
+		`"""`,
+		`" "/`,
+		`#`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, line []byte) {
+		_, err := parseLine(1, line)
+		if err != nil && !isParseError(err) {
+			t.Errorf("parsing %q expected a parse error but got %T: %v", line, err, err)
+		}
+	})
+}
+
+func FuzzReadAll(f *testing.F) {
+	for _, seed := range []string{
+		"Name Age\nScott 33\n",
+		"Name Age # header\n\n# comment\nJane -\r\nJohn \"4\"/\"1\"",
+		"a b\n\"c d\" \"\"\"\" # x\n-- e\n",
+		"a\n\"",
+	} {
+		f.Add([]byte(seed), false)
+		f.Add([]byte(seed), true)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, backslashEscapes bool) {
+		r := NewReader(bytes.NewReader(data))
+		r.BackslashEscapedQuotes = backslashEscapes
+		_, err := r.ReadAll()
+		if err == nil {
+			return
+		}
+		var errs *parseErrorCollection
+		if !errors.As(err, &errs) {
+			t.Fatalf("reading %q expected a parse error collection but got %T: %v", data, err, err)
+		}
+		for _, err := range errs.Errs {
+			if !isParseError(err) {
+				t.Errorf("reading %q expected a parse error but got %T: %v", data, err, err)
+			}
+		}
+	})
+}
@@ -4,10 +4,14 @@ package reader
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	"unicode/utf8"
@@ -23,8 +27,62 @@ var (
 	ErrBareQuote        = errors.New("bare \" in non-quoted-field")
 	ErrReaderEnded      = errors.New("reader ended, nothing left to read")
 	ErrCommentPlacement = errors.New("comments should be the last elements in a row, if immediate preceding lines are null, they cannot be omitted and must be explicitly declared")
+	ErrDuplicateHeader  = errors.New("duplicate header name")
+	ErrLineTooLong      = errors.New("line too long")
 )
 
+// A lineTooLongError is returned when a line is longer than `MaxLineBytes`
+type lineTooLongError struct {
+	Line  int
+	Limit int
+}
+
+func (e *lineTooLongError) Error() string {
+	return fmt.Sprintf("line %d is longer than the limit of %d bytes", e.Line, e.Limit)
+}
+
+func (e *lineTooLongError) Unwrap() error {
+	return ErrLineTooLong
+}
+
+// A duplicateHeaderError is returned when `StrictHeaders` is set and a header name is used by more than one column.
+// Columns are 0-indexed.
+type duplicateHeaderError struct {
+	Line    int
+	Name    string
+	Columns []int
+}
+
+func (e *duplicateHeaderError) Error() string {
+	cols := internal.Map(e.Columns, func(c int, i int, a []int) string {
+		return strconv.Itoa(c)
+	})
+	return fmt.Sprintf("header on line %d: the name %s is used by columns %s", e.Line, internal.SerializeValue(e.Name), strings.Join(cols, ", "))
+}
+
+func (e *duplicateHeaderError) Unwrap() error {
+	return ErrDuplicateHeader
+}
+
+// returns an error for the first header name used by more than one column
+func duplicateHeaders(line int, headers []string) error {
+	for i, name := range headers {
+		if slices.Index(headers, name) != i {
+			continue
+		}
+		cols := []int{i}
+		for j := i + 1; j < len(headers); j++ {
+			if headers[j] == name {
+				cols = append(cols, j)
+			}
+		}
+		if len(cols) > 1 {
+			return &duplicateHeaderError{Line: line, Name: name, Columns: cols}
+		}
+	}
+	return nil
+}
+
 type invalidFieldCountError struct {
 	Line    int
 	RawLine []byte
@@ -65,12 +123,22 @@ type parseError struct {
 	Field          *internal.Field
 	ColumnPosition int
 	RawLine        []byte
+	// The comment text and the name of the header at `FieldPosition` for [ErrCommentPlacement]
+	Comment string
+	Header  string
 }
 
 func (e *parseError) Error() string {
+	if errors.Is(e.Err, ErrCommentPlacement) {
+		return fmt.Sprintf("%s\n%s\nparse error on line %d, column %d: the comment %q is in the %s column: %v", e.RawLine, stringPadLeft("^", e.ColumnPosition), e.Line, e.FieldPosition, "#"+e.Comment, internal.SerializeValue(e.Header), e.Err)
+	}
 	return fmt.Sprintf("%s\n%s\nparse error on line %d, column %d: %v", e.RawLine, stringPadLeft("^", e.ColumnPosition), e.Line, e.FieldPosition, e.Err)
 }
 
+func (e *parseError) Unwrap() error {
+	return e.Err
+}
+
 type parseErrorCollection struct {
 	Errs []error
 }
@@ -96,6 +164,90 @@ type Reader struct {
 	ended               bool
 	firstDataRow        int
 	AllowPartialError   bool
+	// Called with the values of the first line with fields when `IncludesHeader` is true, returning false treats the line
+	// as data and names the columns by their position, `col1`, `col2`, etc.
+	IsHeaderFunc func(fields []string) bool
+	// Return an error reading the header line when more than one column has the same name, by default duplicate
+	// headers are allowed and bind to the first column, see [Reader.IndexedAt]
+	StrictHeaders bool
+	// The number of parse errors [Reader.ReadAll] collects before it stops reading, 0 is unlimited
+	MaxErrors int
+	// The number of physical lines to discard before detecting the header, the lines are still counted for line numbers
+	SkipLines int
+	skipped   int
+	// headers were supplied by [Reader.SetColumnNames] or named by their position after `IsHeaderFunc` returned false,
+	// rather than read from the document
+	columnNames bool
+	// Accept backslash escaped double quotes `\"` and backslashes `\\` within double quoted fields,
+	// in addition to the doubled double quote `""`
+	BackslashEscapedQuotes bool
+	bomChecked             bool
+	// the stream is decoded from UTF-16 so offsets do not match the source
+	transcoded bool
+	// the source the reader was created with, used to seek when building an index
+	src io.Reader
+	// byte offset of the start of every physical line read, only tracked while building an index
+	lineOffsets []int64
+	indexing    bool
+	// The decimal and grouping separators used to recognize numbers by [Reader.InferColumnTypes]
+	NumberLocale NumberLocale
+	// The byte terminating each record instead of the line feed `\n`, such as the ASCII record separator `\x1e`
+	// to read WSV records framed within a larger stream, 0 uses the line feed
+	RecordSeparator byte
+	// Reports whether a rune separates fields, nil uses the whitespace characters of the WSV specification
+	// [internal.IsFieldDelimiter]. Documents created by [Reader.ToDocument] use the same delimiters.
+	IsDelimiter func(r rune) bool
+	// The token read as null instead of `-`, empty uses `-`. With another token a leading `-` is part of the value,
+	// unquoted fields equal to the token are null and quoted ones are values. Documents created by [Reader.ToDocument] use the same token
+	NullString string
+	// Decode the `\uHHHH` and `\\` escapes in values written by a document with `ControlChars` set to [doc.ControlCharsEscape],
+	// after any backslash escaped quotes. Documents created by [Reader.ToDocument] escape them again when written
+	UnescapeControlChars bool
+	// The maximum number of bytes of a single line, not counting the line terminator, reading a longer line returns
+	// an error wrapping ErrLineTooLong and continues with the next line. Guards against unbounded buffering of input
+	// without line feeds, 0 is unlimited
+	MaxLineBytes int
+	// The 0-indexed columns whose text is read verbatim, including any double quotes, and never as null, matching the
+	// columns written by [doc.Document.SetRawColumn]. The header line is read as usual
+	RawColumns []int
+	// A double quoted field left open at the end of a physical line continues on the following lines until its closing
+	// double quote, the lines are joined with line feeds. The line number of the joined line, and of its errors, is the
+	// first physical line. The joined line is limited by `MaxLineBytes`
+	MultilineQuotes bool
+	// Keep the spacing between the fields of each line so a document created by [Reader.ToDocument] writes the lines
+	// as they were read instead of aligning the columns, see [doc.Document.PreserveFormatting]
+	PreserveFormatting bool
+	// The last data line is a summary, such as totals, it is left out of the lines returned by [Reader.Read] and is
+	// available from [Reader.Footer] once the input is read. Reading holds back one data line to detect the last one
+	HasFooterRow bool
+	// The 1-indexed column holding the comment of each line instead of data, for formats with a notes column rather than `#`.
+	// The column is left out of the fields and headers, its value is placed ahead of any `#` comment. 0 reads every column as data
+	CommentColumn int
+	// A blank line ends a section of the document and the next line with fields is read as the header of the next section,
+	// for files with several tables that each have their own header. Comment-only lines do not end a section and
+	// headers set with [Reader.SetColumnNames] are kept. See [Reader.ReadSections]
+	BlankLineSections bool
+	footer            Line
+	// the last data line read and the lines after it, held back until the next data line is read
+	held []pendingLine
+	// lines ready to be returned before reading further
+	ready []pendingLine
+	// the line read by [Reader.Peek], returned by the next read
+	peeked *pendingLine
+}
+
+func (r *Reader) isDelimiter() func(rune) bool {
+	if r.IsDelimiter != nil {
+		return r.IsDelimiter
+	}
+	return internal.IsFieldDelimiter
+}
+
+func (r *Reader) nullString() string {
+	if r.NullString != "" {
+		return r.NullString
+	}
+	return "-"
 }
 
 // Returns a slice of headers for a WSV
@@ -129,14 +281,91 @@ func stringPadLeft(str string, length int) string {
 // - By default it expects a tabular [each record has the same number of fields] document
 //
 // - By default omitted trailing fields for a record are allowed
+//
+// - A UTF-8 or UTF-16 byte order mark at the start of the input is detected and not included in the first value,
+// UTF-16 input is decoded into UTF-8
 func NewReader(r io.Reader) *Reader {
 	return &Reader{
 		br:             bufio.NewReader(r),
+		src:            r,
 		IsTabular:      true,
 		IncludesHeader: true,
 	}
 }
 
+// Resets the reader to read `src` from the start, reusing its buffers to read many inputs with one reader.
+// The exported options such as `IsTabular` and `IncludesHeader` are kept, while the line count, offset, headers,
+// footer and lines read are cleared. Column names set with [Reader.SetColumnNames] or by their position after
+// `IsHeaderFunc` returned false are cleared too, so `src` is read with a header line unless names are set again.
+func (r *Reader) Reset(src io.Reader) {
+	r.br.Reset(src)
+	r.src = src
+	r.numLine = 0
+	r.offset = 0
+	r.rawBuffer = r.rawBuffer[:0]
+	r.lines = nil
+	r.headers = nil
+	r.ended = false
+	r.firstDataRow = 0
+	r.skipped = 0
+	r.columnNames = false
+	r.bomChecked = false
+	r.transcoded = false
+	r.lineOffsets = nil
+	r.footer = nil
+	r.held = nil
+	r.ready = nil
+	r.peeked = nil
+}
+
+// Returns an iterator over the lines of the reader for use with range, yielding each line and the error reading it
+// the same as [Reader.Read]. Stops at the end of the document, the io.EOF is not yielded, and after yielding an error
+// that is not about a single line, such as an error of the underlying reader, since reading again would return it again.
+//
+//	for line, err := range r.Lines() {
+//	  if err != nil {
+//	    return err
+//	  }
+//	}
+func (r *Reader) Lines() iter.Seq2[Line, error] {
+	return func(yield func(Line, error) bool) {
+		for {
+			line, err := r.Read()
+			if err == io.EOF || err == ErrReaderEnded {
+				return
+			}
+			if !yield(line, err) || (err != nil && !isLineError(err)) {
+				return
+			}
+		}
+	}
+}
+
+// Reports whether err is about a single line that could not be read, after which the next line can still be read
+func isLineError(err error) bool {
+	var pe *parseError
+	var fe *invalidFieldCountError
+	var le *lineTooLongError
+	var de *duplicateHeaderError
+	return errors.As(err, &pe) || errors.As(err, &fe) || errors.As(err, &le) || errors.As(err, &de)
+}
+
+// Supplies the column names for a document without a header line, the names are used as the headers
+// and populate the `FieldName` of each field read.
+//
+// The first line is read as data regardless of `r.IncludesHeader`, which is left as configured for the next input
+// after [Reader.Reset]. When `r.IsTabular` is true the first data row must have the same number of fields as names supplied.
+func (r *Reader) SetColumnNames(names []string) {
+	r.headers = slices.Clone(names)
+	r.columnNames = true
+}
+
+// Reports whether the first line with fields is read as the header, the columns are instead named by
+// [Reader.SetColumnNames] or by their position once `IsHeaderFunc` returns false
+func (r *Reader) readsHeader() bool {
+	return r.IncludesHeader && !r.columnNames
+}
+
 // Return the column name at the index i, will return "" if not found
 func (r *Reader) ColumnNameOf(i int) (*string, error) {
 	return internal.GetIndexOfSlice(r.headers, i)
@@ -171,24 +400,96 @@ func Parse(wsvFile string) ([]Line, error) {
 	return records, err
 }
 
+// Reads `r` with the default `NewReader()` collecting the parse errors, stops reading once `n` errors are collected
+// to bound the work spent on a badly broken document. An `n` of 0 or less collects every error. An error reading `r`
+// is collected last since it ends the reading.
+//
+// Returns an empty slice when the document is valid.
+func ValidateN(r io.Reader, n int) []error {
+	rd := NewReader(r)
+	errs := make([]error, 0)
+	for n <= 0 || len(errs) < n {
+		_, err := rd.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, err)
+			if !isLineError(err) {
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// Reads the next data line as a map of the header of each column to the value of the field, skipping the header line,
+// blank lines and comment-only lines. Null fields hold the null token of the reader, `-` unless `r.NullString` is set,
+// and when more than one column has the same header the first column is used.
+//
+// Returns io.EOF once there are no data lines left, and the error of [Reader.Read] for a line that could not be parsed.
+func (r *Reader) ReadRecord() (map[string]string, error) {
+	null := r.nullString()
+	for {
+		line, err := r.Read()
+		if err != nil {
+			return nil, err
+		}
+		if line.IsHeaderLine() || line.FieldCount() == 0 {
+			continue
+		}
+		return unmarshalMap(line.Fields(), unmarshalOptions{nullValue: &null}).Interface().(map[string]string), nil
+	}
+}
+
 // Will read all lines of a reader until it reaches the end of a file or *ParseError
 //
 // If `err == nil`, it has read the entire document successfully
+//
+// When `r.MaxErrors` is greater than 0, stops reading once that many errors are collected and returns the records read so far.
+//
+// An empty document, or one with only blank lines, returns an empty slice and a nil error
 func (r *Reader) ReadAll() (records []Line, err error) {
+	return r.ReadAllContext(context.Background())
+}
+
+// Same as [Reader.ReadAll] checking `ctx` between lines, once `ctx` is canceled or its deadline passes
+// stops reading and returns the records read so far along with `ctx.Err()`.
+func (r *Reader) ReadAllContext(ctx context.Context) (records []Line, err error) {
 	errs := make([]error, 0)
+	records = make([]Line, 0)
 	for {
+		if err := ctx.Err(); err != nil {
+			return records, err
+		}
 		record, err := r.Read()
 		if err == io.EOF {
 			if len(errs) > 0 {
 				return records, &parseErrorCollection{Errs: errs}
 			}
+			if allBlank(records) {
+				return records[:0], nil
+			}
 			return records, nil
 		}
 		if err != nil {
 			errs = append(errs, err)
 		}
 		records = append(records, record)
+		if r.MaxErrors > 0 && len(errs) >= r.MaxErrors {
+			return records, &parseErrorCollection{Errs: errs}
+		}
+	}
+}
+
+// returns true if none of the lines have fields or a comment
+func allBlank(lines []Line) bool {
+	for _, line := range lines {
+		if line == nil || line.FieldCount() > 0 || line.Comment() != "" {
+			return false
+		}
 	}
+	return true
 }
 
 type lineField struct {
@@ -199,52 +500,77 @@ type lineField struct {
 	RawLine   []byte
 }
 
+// Returns the start and end byte offsets of each data field in the line from the positions recorded by parseLine.
+// A field starts after the delimiters following the previous field, and ends at the delimiter recorded as its `Col`.
+// A null or empty quoted field at the end of the line records its last byte instead and the other fields at the end of
+// the line record none.
+func fieldSpans(line []byte, fields []lineField, isDelimiter func(rune) bool) [][2]int {
+	spans := make([][2]int, 0, len(fields))
+	lineEnd := len(bytes.TrimRight(line, "\n"))
+	end := 0
+	for _, field := range fields {
+		if field.IsComment {
+			break
+		}
+		start := end
+		for start < lineEnd && isDelimiter(rune(line[start])) {
+			start++
+		}
+		switch {
+		case field.Col <= start:
+			end = lineEnd
+		case isDelimiter(rune(line[field.Col])):
+			end = field.Col
+		default:
+			end = field.Col + 1
+		}
+		spans = append(spans, [2]int{start, end})
+	}
+	return spans
+}
+
 func parseLine(n int, line []byte) ([]lineField, error) {
-	var b1 *byte = nil
-	var b2 *byte = nil
-	var b3 *byte = nil
-	var b4 *byte = nil
+	return parseLineDelimited(n, line, internal.IsFieldDelimiter, "-")
+}
 
+// Same as parseLine with `isDelimiter` deciding which runes separate fields and `null` the token read as null.
+// When the token is not `-` a leading `-` is read as part of the value and unquoted fields equal to the token are null
+func parseLineDelimited(n int, line []byte, isDelimiter func(rune) bool, null string) ([]lineField, error) {
 	doubleQuoted := false
+	// the current field started with a double quote so it is never read as a custom null token
+	fieldQuoted := false
 
 	isNull := false
 	startDoubleQuote := 0
 	escapedDoubleQuote := 0
 	data := []byte{}
 	str := make([]lineField, 0)
+	literal := literalQuoteSlashQuote(line, isDelimiter)
 	// trim the trailing white space from the line
 	// line = bytes.TrimRightFunc(line, isFieldDelimiter)
 lineLoop:
 	for i, b0 := range line {
-		if b4 != nil {
-			b4 = b3
-			b3 = b2
-			b2 = b1
-			b1 = &b0
-		}
-		if b4 == nil && b3 != nil {
-			b4 = b3
-			b3 = b2
-			b2 = b1
-			b1 = &b0
-		}
-		if b3 == nil && b2 != nil {
-			b3 = b2
-			b2 = b1
-			b1 = &b0
-		}
-		if b2 == nil && b1 != nil {
-			b2 = b1
-			b1 = &b0
-		}
-		if b1 == nil {
-			b1 = &b0
+		// look back at the previous two bytes of the line, reported as absent at the start of the line
+		hasPrev1, hasPrev2 := i >= 1, i >= 2
+		var prev1, prev2 rune
+		if hasPrev1 {
+			prev1 = rune(line[i-1])
+		}
+		if hasPrev2 {
+			prev2 = rune(line[i-2])
 		}
+		// the current byte closes the new line sequence `"/"`, unless it is the literal text `""/""` within a quoted field
+		newLineSeq := hasPrev2 && prev2 == '"' && prev1 == '/' && b0 == '"' && !literal[i]
 		r := rune(b0)
 
 		switch r {
 		case '\n':
-			break lineLoop
+			if !doubleQuoted || !endsQuoted(line[:i], false, isDelimiter) {
+				break lineLoop
+			}
+			// a line feed within a double quoted field, from physical lines joined by `MultilineQuotes`
+			data = append(data, b0)
+			continue
 		case '#':
 			if !doubleQuoted {
 				if len(line[i:]) < 2 {
@@ -261,43 +587,48 @@ lineLoop:
 			data = append(data, byte(r))
 			continue
 		case '"':
-			if bytesToString(b3, b2, b1) == `"/"` {
+			if newLineSeq {
 				data = append(bytes.TrimSuffix(data, []byte{'/'}), byte('\n'))
 				continue
 			}
 
-			if (b2 == nil || internal.IsFieldDelimiter(rune(*b2))) && !doubleQuoted {
+			if (!hasPrev1 || isDelimiter(prev1)) && !doubleQuoted {
 				doubleQuoted = true
+				fieldQuoted = true
 				startDoubleQuote = i
 				continue
 			}
 
-			if (b3 == nil || internal.IsFieldDelimiter(rune(*b3))) && b2 != nil && rune(*b2) == '"' && (len(line)-1 == i || (len(line)-1 > i && internal.IsFieldDelimiter(nextRune(line[i+1:])))) {
+			if (!hasPrev2 || isDelimiter(prev2)) && hasPrev1 && prev1 == '"' && (len(line)-1 == i || (len(line)-1 > i && isDelimiter(nextRune(line[i+1:])))) {
 				data = []byte{}
 				str = append(str, lineField{IsComment: false, Value: string(data), IsNull: isNull, Col: i, RawLine: line})
 				doubleQuoted = false
+				fieldQuoted = false
 				continue
 			}
 
-			if b2 != nil && rune(*b2) == '"' && (b3 == nil || rune(*b3) != '"') && !(len(line)-1 > i+1 && internal.IsFieldDelimiter(nextRune(line[i+1:])) && b3 != nil && rune(*b3) == '/') && !(len(line)-1 > i+2 && nextRune(line[i+1:]) == '/' && nextRune(line[i+2:]) == '"') {
+			// a doubled double quote next to a new line sequence `"/"` is part of the sequence, unless the sequence is literal text
+			nextToNewLine := !(i > 0 && literal[i-1]) && len(line)-1 > i+1 && isDelimiter(nextRune(line[i+1:])) && hasPrev2 && prev2 == '/'
+			beforeNewLine := !(i+2 < len(line) && literal[i+2]) && len(line)-1 > i+2 && nextRune(line[i+1:]) == '/' && nextRune(line[i+2:]) == '"'
+			if hasPrev1 && prev1 == '"' && (!hasPrev2 || prev2 != '"') && !nextToNewLine && !beforeNewLine {
 				data = append(data, byte('"'))
 				escapedDoubleQuote = i
 				continue
 			}
 
-			if doubleQuoted && (len(line)-1 == i || (len(line)-1 > i && internal.IsFieldDelimiter(nextRune(line[i+1:])))) && (b2 == nil || rune(*b2) != '"' || i > escapedDoubleQuote) {
+			if doubleQuoted && (len(line)-1 == i || (len(line)-1 > i && isDelimiter(nextRune(line[i+1:])))) && (!hasPrev1 || prev1 != '"' || i > escapedDoubleQuote) {
 				doubleQuoted = false
 
 			}
 
 		case '-':
-			if r == '-' && (b2 == nil || internal.IsFieldDelimiter(rune(*b2))) && !doubleQuoted {
+			if r == '-' && null == "-" && (!hasPrev1 || isDelimiter(prev1)) && !doubleQuoted {
 				isNull = true
 			}
 			fallthrough
 		default:
 
-			if bytesToString(b3, b2, b1) == `"/"` {
+			if newLineSeq {
 				data = append(bytes.TrimSuffix(data, []byte{'/'}), byte('\n'))
 			}
 			if isNull && (len(line)-1 == i) {
@@ -305,9 +636,9 @@ lineLoop:
 				break lineLoop
 			}
 			// currently flagged as null but has more characters left to parse and
-			if isNull && len(line)-1 > i && bytes.IndexFunc(line[i:], internal.IsFieldDelimiter) != 1 {
+			if isNull && len(line)-1 > i && bytes.IndexFunc(line[i:], isDelimiter) != 1 {
 				// the next immediate character is a white space
-				if b2 != nil && rune(*b2) == '-' && bytes.IndexFunc([]byte{*b1}, internal.IsFieldDelimiter) == 0 {
+				if hasPrev1 && prev1 == '-' && isDelimiter(r) {
 					data = []byte{}
 				} else {
 					// and is not surround by double quotes we have an invalid
@@ -316,7 +647,7 @@ lineLoop:
 
 			}
 
-			isDelim := internal.IsFieldDelimiter(r)
+			isDelim := isDelimiter(r)
 			if isDelim && (!doubleQuoted) {
 				if len(data) == 0 && !isNull {
 					continue
@@ -324,8 +655,13 @@ lineLoop:
 				if string(data) == `"` {
 					return str, &parseError{Line: n, Err: ErrBareQuote, FieldPosition: i, RawLine: line}
 				}
-				str = append(str, lineField{IsComment: false, Value: string(data), IsNull: isNull, Col: i, RawLine: line})
+				if null != "-" && !fieldQuoted && string(data) == null {
+					str = append(str, lineField{IsComment: false, Value: "", IsNull: true, Col: i, RawLine: line})
+				} else {
+					str = append(str, lineField{IsComment: false, Value: string(data), IsNull: isNull, Col: i, RawLine: line})
+				}
 				isNull = false
+				fieldQuoted = false
 				data = []byte{}
 				continue
 			}
@@ -345,21 +681,178 @@ lineLoop:
 		if string(data) == `"` {
 			return str, &parseError{Line: n, Err: ErrBareQuote, FieldPosition: startDoubleQuote, RawLine: line, ColumnPosition: startDoubleQuote}
 		}
+		if null != "-" && !fieldQuoted && string(data) == null {
+			return append(str, lineField{IsComment: false, Value: "", IsNull: true, RawLine: line}), nil
+		}
 		str = append(str, lineField{IsComment: false, Value: string(data), IsNull: isNull, RawLine: line})
 
 	}
 	return str, nil
 }
 
-func bytesToString(s ...*byte) string {
-	str := ""
-	for _, b := range s {
-		if b == nil {
+// Marks the last byte of each `"/"` within a double quoted field that starts with the second double quote of a doubled
+// double quote `""`, such as in `"""/"""`, or with the opening double quote, such as in `"/"""`, so the literal text
+// `"/"` written as `""/""` is not read as a new line
+func literalQuoteSlashQuote(line []byte, isDelimiter func(rune) bool) []bool {
+	marks := make([]bool, len(line))
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		b := line[i]
+		if !quoted {
+			if b == '#' {
+				break
+			}
+			if b == '"' && (i == 0 || isDelimiter(rune(line[i-1]))) {
+				quoted = true
+				// an opening double quote followed by `/"` starts the value with a slash
+				if i+2 < len(line) && line[i+1] == '/' && line[i+2] == '"' {
+					marks[i+2] = true
+				}
+			}
 			continue
 		}
-		str = str + string(*b)
+		if b != '"' {
+			continue
+		}
+		switch {
+		case i+1 < len(line) && line[i+1] == '"':
+			if i+3 < len(line) && line[i+2] == '/' && line[i+3] == '"' {
+				marks[i+3] = true
+			}
+			i++
+		case i+2 < len(line) && line[i+1] == '/' && line[i+2] == '"':
+			i += 2
+		default:
+			quoted = false
+		}
 	}
-	return str
+	return marks
+}
+
+// Moves the data field at the 0-indexed column `col` into the comment of the line, ahead of a `#` comment.
+// The field is dropped from a header line, and null or empty fields do not add a comment.
+func moveToComment(fields []lineField, spans [][2]int, col int, header bool) ([]lineField, [][2]int) {
+	if col >= len(fields) || fields[col].IsComment {
+		return fields, spans
+	}
+	field := fields[col]
+	fields = slices.Delete(fields, col, col+1)
+	if col < len(spans) {
+		spans = slices.Delete(spans, col, col+1)
+	}
+	if header || field.IsNull || field.Value == "" {
+		return fields, spans
+	}
+	if last := len(fields) - 1; last >= 0 && fields[last].IsComment {
+		sep := " "
+		if strings.HasPrefix(fields[last].Value, " ") {
+			sep = ""
+		}
+		fields[last].Value = field.Value + sep + fields[last].Value
+		return fields, spans
+	}
+	return append(fields, lineField{IsComment: true, Value: field.Value, Col: field.Col, RawLine: field.RawLine}), spans
+}
+
+// Rewrites the backslash escapes within double quoted fields, `\"` and `\\`, into the form parsed by parseLine, along with
+// the offset in line of each byte rewritten and the length of line after the last one
+func unescapeBackslashes(line []byte, isDelimiter func(rune) bool) ([]byte, []int) {
+	out := make([]byte, 0, len(line))
+	offsets := make([]int, 0, len(line)+1)
+	// appends the bytes written for the text starting at the byte i of line
+	emit := func(i int, b ...byte) {
+		out = append(out, b...)
+		for range b {
+			offsets = append(offsets, i)
+		}
+	}
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		b := line[i]
+		if !quoted {
+			if b == '#' {
+				// the rest of the line is a comment
+				for j := i; j < len(line); j++ {
+					emit(j, line[j])
+				}
+				break
+			}
+			fieldStart := i == 0 || isDelimiter(rune(line[i-1]))
+			if b == '"' && fieldStart && i+1 < len(line) && line[i+1] == '"' && (i+2 == len(line) || isDelimiter(rune(line[i+2]))) {
+				// an empty quoted field, not a doubled double quote
+				emit(i, '"', '"')
+				i++
+				continue
+			}
+			if b == '"' && fieldStart {
+				quoted = true
+			}
+			emit(i, b)
+			continue
+		}
+		next := byte(0)
+		if i+1 < len(line) {
+			next = line[i+1]
+		}
+		switch {
+		case b == '\\' && next == '"':
+			emit(i, '"', '"')
+			i++
+		case b == '\\' && next == '\\':
+			emit(i, '\\')
+			i++
+		case b == '"' && next == '"':
+			// doubled quotes are still accepted
+			emit(i, '"')
+			emit(i+1, '"')
+			i++
+		case b == '"' && next == '/' && i+2 < len(line) && line[i+2] == '"':
+			// the new line sequence `"/"` continues the quoted field
+			for j := i; j < i+3; j++ {
+				emit(j, line[j])
+			}
+			i += 2
+		case b == '"':
+			quoted = false
+			emit(i, b)
+		default:
+			emit(i, b)
+		}
+	}
+	return out, append(offsets, len(line))
+}
+
+// Reports whether the line ends within a double quoted field, skipping comments, doubled double quotes `""`, the line
+// feed escape `"/"` and when `backslashEscapes` is true backslash escapes. A bare double quote is left for parseLine to report
+func endsQuoted(line []byte, backslashEscapes bool, isDelimiter func(rune) bool) bool {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		b := line[i]
+		if !quoted {
+			if b == '#' {
+				return false
+			}
+			if b == '"' && (i == 0 || isDelimiter(rune(line[i-1]))) {
+				quoted = true
+			}
+			continue
+		}
+		next := byte(0)
+		if i+1 < len(line) {
+			next = line[i+1]
+		}
+		switch {
+		case backslashEscapes && b == '\\':
+			i++
+		case b == '"' && next == '"':
+			i++
+		case b == '"' && next == '/' && i+2 < len(line) && line[i+2] == '"':
+			i += 2
+		case b == '"':
+			quoted = false
+		}
+	}
+	return quoted
 }
 
 // Returns the line number of the reader
@@ -378,8 +871,77 @@ func (r *Reader) CurrentRow() int {
 //
 // - If there is no data left to be read, `r.Read()` returns a *Line with an empty slice Fields and io.EOF.
 //
+// - The first `r.SkipLines` physical lines are discarded before any line is parsed, if the input ends before then io.EOF is returned.
+//
+// - When `r.HasFooterRow` is set the last data line is not returned, see [Reader.Footer].
+//
 // - Subsequent calls to `r.Read()` after io.EOF returns a nil and ErrReaderEnded
 func (r *Reader) Read() (Line, error) {
+	if r.peeked != nil {
+		p := r.peeked
+		r.peeked = nil
+		return p.line, p.err
+	}
+	if r.HasFooterRow {
+		return r.readBeforeFooter()
+	}
+	return r.read()
+}
+
+// Returns the next line and the error reading it the same as [Reader.Read] without consuming it, the next call to
+// `r.Read()` returns the same line and error. The line is only read once, so peeking the header line registers the
+// headers a single time and calling `r.Peek()` again returns the same line.
+func (r *Reader) Peek() (Line, error) {
+	if r.peeked == nil {
+		line, err := r.Read()
+		r.peeked = &pendingLine{line, err}
+	}
+	return r.peeked.line, r.peeked.err
+}
+
+// a line and the error returned reading it
+type pendingLine struct {
+	line Line
+	err  error
+}
+
+// Reads one data line ahead so the last data line can be held back as the footer, lines after a data line are
+// held with it to keep the order of the lines.
+func (r *Reader) readBeforeFooter() (Line, error) {
+	for {
+		if len(r.ready) > 0 {
+			p := r.ready[0]
+			r.ready = r.ready[1:]
+			return p.line, p.err
+		}
+		line, err := r.read()
+		if err == io.EOF && r.held != nil {
+			r.footer = r.held[0].line
+			r.lines = slices.DeleteFunc(r.lines, func(l Line) bool { return l == r.footer })
+			r.ready = append(r.held[1:], pendingLine{line, err})
+			r.held = nil
+			continue
+		}
+		if err == nil && line.FieldCount() > 0 && !line.IsHeaderLine() {
+			r.ready = append(r.ready, r.held...)
+			r.held = []pendingLine{{line, nil}}
+			continue
+		}
+		if r.held != nil {
+			r.held = append(r.held, pendingLine{line, err})
+			continue
+		}
+		return line, err
+	}
+}
+
+// Returns the last data line of the document when `r.HasFooterRow` is set, once the reader has reached the end
+// of the input. Returns nil before then or if the document does not have data lines.
+func (r *Reader) Footer() Line {
+	return r.footer
+}
+
+func (r *Reader) read() (Line, error) {
 	var data []byte
 	var errRead error
 	if r.ended {
@@ -389,34 +951,146 @@ func (r *Reader) Read() (Line, error) {
 		fields:     make([]internal.Field, 0),
 		fieldCount: 0,
 	}
+	for r.skipped < r.SkipLines {
+		_, errRead = r.readLine()
+		if errRead == io.EOF {
+			r.ended = true
+			return &line, io.EOF
+		}
+		r.skipped++
+	}
 	data, errRead = r.readLine()
 	if errRead == io.EOF {
 		r.ended = true
 		return &line, io.EOF
 	}
 	line.line = r.numLine
+	if errRead != nil {
+		return &line, errRead
+	}
+	if r.MultilineQuotes {
+		data, errRead = r.readQuotedLines(data)
+		if errRead != nil {
+			return &line, errRead
+		}
+	}
 
-	fields, errRead := parseLine(r.numLine, data)
+	isDelimiter := r.isDelimiter()
+	// the text of the fields for `RawColumns`, before any escapes are rewritten
+	raw := data
+	// the offset in raw of each byte of data when escapes are rewritten
+	var offsets []int
+	if r.BackslashEscapedQuotes {
+		data, offsets = unescapeBackslashes(data, isDelimiter)
+	}
+	fields, errRead := parseLineDelimited(line.line, data, isDelimiter, r.nullString())
 	if errRead != nil {
 		return &line, errRead
 	}
+	spans := fieldSpans(data, fields, isDelimiter)
+	if offsets != nil {
+		for i := range spans {
+			spans[i] = [2]int{offsets[spans[i][0]], offsets[spans[i][1]]}
+		}
+	}
+	if r.PreserveFormatting {
+		format := internal.NewLineFormat(string(raw), spans)
+		line.format = &format
+	}
+	if r.BlankLineSections && len(fields) == 0 && r.firstDataRow != 0 && !r.columnNames {
+		// the blank line ends the section, the next line with fields is the header of the next section
+		r.firstDataRow = 0
+		r.headers = nil
+	}
 	if len(fields) > 0 && r.firstDataRow == 0 && !fields[0].IsComment {
-		r.firstDataRow = r.numLine
-		if r.IncludesHeader {
+		r.firstDataRow = line.line
+		if r.readsHeader() && r.IsHeaderFunc != nil {
+			values := make([]string, 0, len(fields))
+			for _, field := range fields {
+				if field.IsComment {
+					continue
+				}
+				if field.IsNull {
+					values = append(values, r.nullString())
+					continue
+				}
+				values = append(values, field.Value)
+			}
+			if !r.IsHeaderFunc(values) {
+				// the names only last until the reader is reset, `IncludesHeader` is left as configured
+				r.headers = make([]string, len(values))
+				for i := range values {
+					r.headers[i] = internal.PositionalColumnName(i)
+				}
+				r.columnNames = true
+			}
+		}
+		if r.readsHeader() {
 			line.isHeaderLine = true
 		}
 	}
 
+	if r.UnescapeControlChars {
+		for i := range fields {
+			if !fields[i].IsComment {
+				fields[i].Value = internal.UnescapeControlChars(fields[i].Value)
+			}
+		}
+	}
+
+	if r.CommentColumn > 0 {
+		fields, spans = moveToComment(fields, spans, r.CommentColumn-1, line.isHeaderLine)
+	}
+
+	if len(r.RawColumns) > 0 && !line.isHeaderLine {
+		for i := range fields {
+			if i < len(spans) && !fields[i].IsComment && slices.Contains(r.RawColumns, i) {
+				fields[i].Value = string(raw[spans[i][0]:spans[i][1]])
+				fields[i].IsNull = false
+			}
+		}
+	}
+
+	if line.line == r.firstDataRow && r.columnNames && r.IsTabular {
+		values := make([]string, 0, len(fields))
+		for _, field := range fields {
+			if field.IsComment {
+				continue
+			}
+			if field.IsNull {
+				values = append(values, r.nullString())
+				continue
+			}
+			values = append(values, internal.SerializeValue(field.Value))
+		}
+		if len(values) != len(r.headers) {
+			return &line, &invalidFieldCountError{
+				Fields:  values,
+				Headers: r.headers,
+				Line:    line.line,
+				RawLine: data,
+			}
+		}
+	}
+
 	for i, field := range fields {
-		if r.numLine == r.firstDataRow && r.IncludesHeader && !field.IsComment {
-			r.headers = append(r.headers, field.Value)
+		if line.line == r.firstDataRow && r.readsHeader() && !field.IsComment {
+			// null and empty headers are named by their position so their columns can be bound by name
+			if field.IsNull || field.Value == "" {
+				r.headers = append(r.headers, internal.PositionalColumnName(len(r.headers)))
+			} else {
+				r.headers = append(r.headers, field.Value)
+			}
 			d := internal.Field{Value: field.Value}
 			if field.IsNull {
 				d.IsNull = true
 			}
 			d.IsHeader = true
 			d.FieldIndex = i
-			d.RowIndex = r.numLine
+			d.RowIndex = line.line
+			if i < len(spans) {
+				d.SetRange(spans[i][0], spans[i][1])
+			}
 			line.fields = append(line.fields, d)
 			line.fieldCount++
 			continue
@@ -426,17 +1100,17 @@ func (r *Reader) Read() (Line, error) {
 			// comments must be the first and only value or the last value parsed, if preceding fields are not explicitly defined return an error
 			// the exception being non-tabular documents
 			if i < len(r.headers) && i != 0 && r.IsTabular {
-				return &line, &parseError{Line: r.numLine, FieldPosition: i + 1, Err: ErrCommentPlacement, ColumnPosition: field.Col, RawLine: field.RawLine}
+				return &line, &parseError{Line: line.line, FieldPosition: i + 1, Err: ErrCommentPlacement, ColumnPosition: field.Col, RawLine: field.RawLine, Comment: field.Value, Header: r.headers[i]}
 			}
 			line.comment = field.Value
 			continue
 		}
 		line.fieldCount++
 
-		if r.IsTabular && r.IncludesHeader && len(r.headers) < line.fieldCount {
+		if r.IsTabular && r.readsHeader() && len(r.headers) < line.fieldCount {
 			extraFields := internal.Map(fields[i:], func(e lineField, i int, _ []lineField) string {
 				if e.IsNull {
-					return "-"
+					return r.nullString()
 				}
 				return internal.SerializeValue(e.Value)
 			})
@@ -449,11 +1123,14 @@ func (r *Reader) Read() (Line, error) {
 		}
 
 		fieldName := columnName(r.headers, i)
-		d := internal.Field{Value: field.Value, FieldName: fieldName, IsHeader: false, RowIndex: r.numLine, FieldIndex: i, IsNull: false}
+		d := internal.Field{Value: field.Value, FieldName: fieldName, IsHeader: false, RowIndex: line.line, FieldIndex: i, IsNull: false}
 		if field.IsNull {
 			d.IsNull = true
 			d.Value = ""
 		}
+		if i < len(spans) {
+			d.SetRange(spans[i][0], spans[i][1])
+		}
 		line.fields = append(line.fields, d)
 	}
 
@@ -465,18 +1142,24 @@ func (r *Reader) Read() (Line, error) {
 		return &line, errRead
 	}
 
-	if r.numLine != 1 && r.NullTrailingColumns && len(line.fields) < len(r.headers) {
+	if line.isHeaderLine && r.StrictHeaders {
+		if err := duplicateHeaders(line.line, r.headers); err != nil {
+			return &line, err
+		}
+	}
+
+	if line.line != 1 && r.NullTrailingColumns && len(line.fields) < len(r.headers) {
 		x := len(r.headers) - len(line.fields)
 		o := len(line.fields)
 		for i := range x {
 			h := o + i
 			cname := columnName(r.headers, h)
-			rec := internal.Field{IsNull: true, Value: "", FieldIndex: h, RowIndex: r.numLine, FieldName: cname, IsHeader: false}
+			rec := internal.Field{IsNull: true, Value: "", FieldIndex: h, RowIndex: line.line, FieldName: cname, IsHeader: false}
 			line.fields = append(line.fields, rec)
 			line.fieldCount++
 		}
 	}
-	if r.IsTabular && r.IncludesHeader && !r.NullTrailingColumns && len(r.headers) > line.fieldCount {
+	if r.IsTabular && r.readsHeader() && !r.NullTrailingColumns && len(r.headers) > line.fieldCount {
 		return &line, &invalidFieldCountError{
 			Fields:  line.FieldsValues(),
 			Headers: r.headers,
@@ -495,17 +1178,36 @@ func nextRune(b []byte) rune {
 }
 
 // Reads the current line into a slice bytes
+//
+// A byte order mark at the start of the stream is not included in the line, UTF-16 streams are decoded into UTF-8
 func (r *Reader) readLine() ([]byte, error) {
-	line, err := r.br.ReadSlice(internal.CharLineFeed)
+	if !r.bomChecked {
+		r.detectBOM()
+	}
+	if r.indexing {
+		r.lineOffsets = append(r.lineOffsets, r.offset)
+	}
+	sep := byte(internal.CharLineFeed)
+	if r.RecordSeparator != 0 {
+		sep = r.RecordSeparator
+	}
+	line, err := r.br.ReadSlice(sep)
 	if err == bufio.ErrBufferFull {
 		r.rawBuffer = append(r.rawBuffer[:0], line...)
 		for err == bufio.ErrBufferFull {
-			line, err = r.br.ReadSlice(internal.CharLineFeed)
+			if r.MaxLineBytes > 0 && len(r.rawBuffer) > r.MaxLineBytes {
+				return nil, r.discardLine(sep, len(r.rawBuffer))
+			}
+			line, err = r.br.ReadSlice(sep)
 			r.rawBuffer = append(r.rawBuffer, line...)
 		}
 		line = r.rawBuffer
 	}
 	readSize := len(line)
+	if r.indexing && readSize == 0 && err == io.EOF {
+		// there was no line to read at the offset
+		r.lineOffsets = r.lineOffsets[:len(r.lineOffsets)-1]
+	}
 	if readSize > 0 && err == io.EOF {
 		err = nil
 		// For backwards compatibility, drop trailing \r before EOF.
@@ -515,44 +1217,183 @@ func (r *Reader) readLine() ([]byte, error) {
 	}
 	r.numLine++
 	r.offset += int64(readSize)
-	if n := len(line); n >= 2 && line[n-2] == internal.CharCarriageReturn && line[n-1] == internal.CharLineFeed {
-		line[n-2] = internal.CharLineFeed
-		line = line[:n-1]
+	if sep != internal.CharLineFeed {
+		// trim the trailing record separator
+		line = bytes.TrimSuffix(line, []byte{sep})
+	} else {
+		if n := len(line); n >= 2 && line[n-2] == internal.CharCarriageReturn && line[n-1] == internal.CharLineFeed {
+			line[n-2] = internal.CharLineFeed
+			line = line[:n-1]
+		}
+		// trim the trailing new line
+		line = bytes.TrimSuffix(line, []byte("\n"))
+	}
+	if r.MaxLineBytes > 0 && len(line) > r.MaxLineBytes {
+		return nil, &lineTooLongError{Line: r.numLine, Limit: r.MaxLineBytes}
 	}
-	// trim the trailing new line
-	line = bytes.TrimSuffix(line, []byte("\n"))
 	return line, err
 }
 
+// Joins the physical lines following `data` with line feeds until the double quoted field open at the end of `data`
+// closes, parseLine keeps the line feeds within the quoted field. An unterminated field at the end of the input is left
+// for parseLine to report
+func (r *Reader) readQuotedLines(data []byte) ([]byte, error) {
+	isDelimiter := r.isDelimiter()
+	if !endsQuoted(data, r.BackslashEscapedQuotes, isDelimiter) {
+		return data, nil
+	}
+	// the next read reuses the buffer of `data`
+	joined := slices.Clone(data)
+	for endsQuoted(joined, r.BackslashEscapedQuotes, isDelimiter) {
+		next, err := r.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		joined = append(append(joined, internal.CharLineFeed), next...)
+		if r.MaxLineBytes > 0 && len(joined) > r.MaxLineBytes {
+			return nil, &lineTooLongError{Line: r.numLine, Limit: r.MaxLineBytes}
+		}
+	}
+	return joined, nil
+}
+
+// Discards the rest of a line longer than `MaxLineBytes` without buffering it, `read` bytes of the line were already
+// read. The line is counted so reading continues with the next line.
+func (r *Reader) discardLine(sep byte, read int) error {
+	var err error = bufio.ErrBufferFull
+	for err == bufio.ErrBufferFull {
+		var b []byte
+		b, err = r.br.ReadSlice(sep)
+		read += len(b)
+	}
+	r.rawBuffer = r.rawBuffer[:0]
+	r.numLine++
+	r.offset += int64(read)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	return &lineTooLongError{Line: r.numLine, Limit: r.MaxLineBytes}
+}
+
 // Takes a reader an turns that into a document
+//
+// Blank lines, comment-only lines and the position of the header line are preserved, so writing a formatted
+// document back out with [doc.Document.WriteAll] reproduces the original input. The whitespace between fields,
+// and before comments, is normalized by the document when written unless `r.PreserveFormatting` is set.
 func (r *Reader) ToDocument() (*doc.Document, error) {
-	doc := doc.NewDocument()
-	var err error
-	var rl Line
+	d := r.newDocument()
 	for {
-		rl, err = r.Read()
+		rl, err := r.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return doc, err
+			return d, err
+		}
+		if err = appendToDocument(d, rl); err != nil {
+			return d, err
+		}
+	}
+
+	return d, nil
+}
+
+// Same as [Reader.ToDocument] but lines that fail to parse are left out of the document instead of stopping,
+// returning the document of every line that could be read along with the errors collected.
+//
+// The lines of the document are renumbered when a line is left out.
+func (r *Reader) ToDocumentPartial() (*doc.Document, []error) {
+	d := r.newDocument()
+	errs := make([]error, 0)
+	for {
+		rl, err := r.Read()
+		if err == io.EOF {
+			break
 		}
-		line, err := doc.AddLine()
 		if err != nil {
-			return nil, err
+			errs = append(errs, err)
+			continue
 		}
-		if rl.Comment() != "" {
-			line.UpdateComment(rl.Comment())
+		if err = appendToDocument(d, rl); err != nil {
+			errs = append(errs, err)
 		}
-		for i := range rl.FieldCount() {
-			field, _ := rl.Field(i)
-			if field.IsNull {
-				line.AppendNull()
-			} else {
-				line.Append(field.Value)
+	}
+
+	return d, errs
+}
+
+// Reads the rest of the input as sections separated by blank lines, each with its own header, returning a document
+// for every section in order. Sets `r.BlankLineSections`, comment-only lines belong to the section they are in or
+// the one they precede, and the blank lines between sections are left out of the documents.
+//
+// Stops at the first line that could not be read, returning the sections read so far along with the error.
+func (r *Reader) ReadSections() ([]*doc.Document, error) {
+	r.BlankLineSections = true
+	sections := make([]*doc.Document, 0)
+	d := r.newDocument()
+	for {
+		rl, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sections, err
+		}
+		if rl.FieldCount() == 0 && rl.Comment() == "" {
+			if len(d.Headers()) > 0 {
+				sections = append(sections, d)
+				d = r.newDocument()
 			}
+			continue
+		}
+		if err = appendToDocument(d, rl); err != nil {
+			return sections, err
 		}
 	}
+	if d.LineCount() > 0 {
+		sections = append(sections, d)
+	}
+	return sections, nil
+}
 
-	return doc, nil
+// returns an empty document with the options of the reader that affect writing
+func (r *Reader) newDocument() *doc.Document {
+	d := doc.NewDocument()
+	d.Tabular = r.IsTabular
+	d.IsDelimiter = r.IsDelimiter
+	d.NullString = r.NullString
+	if r.UnescapeControlChars {
+		d.ControlChars = doc.ControlCharsEscape
+	}
+	d.PreserveFormatting = r.PreserveFormatting
+	return d
+}
+
+// adds a line to the document with the fields and comment of the line read
+func appendToDocument(d *doc.Document, rl Line) error {
+	line, err := d.AddLine()
+	if err != nil {
+		return err
+	}
+	if rl.Comment() != "" {
+		line.UpdateComment(rl.Comment())
+	}
+	for i := range rl.FieldCount() {
+		field, _ := rl.Field(i)
+		if field.IsNull {
+			err = line.AppendNull()
+		} else {
+			err = line.Append(field.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if rl, ok := rl.(*readerLine); ok && rl.format != nil {
+		d.SetLineFormat(line, *rl.format)
+	}
+	return nil
 }
@@ -2,6 +2,8 @@ package reader
 
 import (
 	"errors"
+	"strconv"
+	"time"
 
 	"github.com/campfhir/wsv/internal"
 )
@@ -9,11 +11,14 @@ import (
 var (
 	ErrFieldNotFound = errors.New("field does not exist")
 	ErrEndOfLine     = errors.New("no more fields left in this line")
+	ErrNullField     = errors.New("field is null")
 )
 
 type Line interface {
 	// Returns the field value at the 0-index or `ErrFieldNotFound` if out of bounds
 	Field(fi int) (*internal.Field, error)
+	// Returns the first field with the field name or `ErrFieldNotFound` if there is none
+	FieldByName(name string) (*internal.Field, error)
 	// Get the value of comment for the line
 	Comment() string
 	// Get the line number
@@ -28,6 +33,19 @@ type Line interface {
 	FieldsValues() []string
 	// All the fields in the line
 	Fields() []internal.Field
+	// Parses the field at the 0-index as a base 10 integer, returns `ErrNullField` if the field is null
+	FieldInt(fi int) (int64, error)
+	// Parses the field at the 0-index as a `True|False` bool, returns `ErrNullField` if the field is null
+	FieldBool(fi int) (bool, error)
+	// Parses the field at the 0-index as a time with the layout, which can be a shorthand such as `dateonly`,
+	// returns `ErrNullField` if the field is null
+	FieldTime(fi int, layout string) (time.Time, error)
+	// Same as FieldInt for the first field with the field name
+	FieldIntByName(name string) (int64, error)
+	// Same as FieldBool for the first field with the field name
+	FieldBoolByName(name string) (bool, error)
+	// Same as FieldTime for the first field with the field name
+	FieldTimeByName(name string, layout string) (time.Time, error)
 }
 
 type readerLine struct {
@@ -39,6 +57,8 @@ type readerLine struct {
 	fieldCount   int
 	currentField int
 	isHeaderLine bool
+	// the original spacing of the line, only kept when `PreserveFormatting` is set
+	format *internal.LineFormat
 }
 
 // A slice of all the fields in this line
@@ -94,3 +114,73 @@ func (line *readerLine) FieldsValues() []string {
 			return e.SerializeText()
 		})
 }
+
+func (line *readerLine) FieldInt(fi int) (int64, error) {
+	return parseInt(line.Field(fi))
+}
+
+func (line *readerLine) FieldBool(fi int) (bool, error) {
+	return parseBool(line.Field(fi))
+}
+
+func (line *readerLine) FieldTime(fi int, layout string) (time.Time, error) {
+	return parseTime(layout)(line.Field(fi))
+}
+
+func (line *readerLine) FieldIntByName(name string) (int64, error) {
+	return parseInt(line.FieldByName(name))
+}
+
+func (line *readerLine) FieldBoolByName(name string) (bool, error) {
+	return parseBool(line.FieldByName(name))
+}
+
+func (line *readerLine) FieldTimeByName(name string, layout string) (time.Time, error) {
+	return parseTime(layout)(line.FieldByName(name))
+}
+
+// Returns the first field with the field name, duplicated headers resolve to the first column with the header
+func (line *readerLine) FieldByName(name string) (*internal.Field, error) {
+	for i := range line.fields {
+		if line.fields[i].FieldName == name {
+			return &line.fields[i], nil
+		}
+	}
+	return nil, ErrFieldNotFound
+}
+
+func nonNull(field *internal.Field, err error) (*internal.Field, error) {
+	if err != nil {
+		return nil, err
+	}
+	if field.IsNull {
+		return nil, ErrNullField
+	}
+	return field, nil
+}
+
+func parseInt(field *internal.Field, err error) (int64, error) {
+	field, err = nonNull(field, err)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(field.Value, 10, 64)
+}
+
+func parseBool(field *internal.Field, err error) (bool, error) {
+	field, err = nonNull(field, err)
+	if err != nil {
+		return false, err
+	}
+	return internal.ParseBool(field.Value, "True|False")
+}
+
+func parseTime(layout string) func(field *internal.Field, err error) (time.Time, error) {
+	return func(field *internal.Field, err error) (time.Time, error) {
+		field, err = nonNull(field, err)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Parse(internal.ParseStructTagDateFormat(layout), field.Value)
+	}
+}
@@ -2,12 +2,19 @@ package reader
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
+	"unicode/utf16"
 	"unicode/utf8"
 
 	doc "github.com/campfhir/wsv/document"
@@ -1761,7 +1768,16 @@ func TestReadInvalidCommentPlacementDueToOmittedFields(t *testing.T) {
 		t.Error("expected to return an error but did not", line)
 		return
 	}
-
+	if !errors.Is(err, ErrCommentPlacement) {
+		t.Error("expected the error to wrap ErrCommentPlacement but got", err)
+	}
+	var pe *parseError
+	if !errors.As(err, &pe) || pe.FieldPosition != 3 || pe.Header != "team" || !strings.HasPrefix(pe.Comment, "invalid comment placement") {
+		t.Fatal("expected the comment and the team header in the parse error but got", err)
+	}
+	if !strings.Contains(err.Error(), `"#invalid comment placement,`) || !strings.Contains(err.Error(), "in the team column") {
+		t.Error("expected the comment and header in the message but got", err)
+	}
 }
 
 func TestReadComplexValues(t *testing.T) {
@@ -2890,3 +2906,1381 @@ func TestLargeAnotherRandomSortByMultipleColumns(t *testing.T) {
 		t.Error("\n" + string(data))
 	}
 }
+
+func TestSkipLinesBeforeHeader(t *testing.T) {
+	lines := []string{
+		`Exported  2024-01-01`,
+		`#generated by some tool`,
+		`Rows  2`,
+		`Name   Age`,
+		`Scott  33`,
+		`Jane   79`,
+	}
+	r := NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	r.SkipLines = 3
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatal("expected 3 lines but got", len(records))
+	}
+	if !records[0].IsHeaderLine() || records[0].LineNumber() != 4 {
+		t.Errorf("expected line 4 to be the header but got line %d", records[0].LineNumber())
+	}
+	if len(r.Headers()) != 2 || r.Headers()[0] != "Name" || r.Headers()[1] != "Age" {
+		t.Error("expected the headers to be [Name Age] but got", r.Headers())
+	}
+	field, err := records[2].Field(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.Value != "Jane" || field.FieldName != "Name" || field.RowIndex != 6 {
+		t.Errorf("expected Jane in the Name column on line 6 but got %+v", field)
+	}
+}
+
+func TestSkipLinesPastEOF(t *testing.T) {
+	r := NewReader(strings.NewReader("banner\nanother banner\n"))
+	r.SkipLines = 5
+	_, err := r.Read()
+	if err != io.EOF {
+		t.Error("expected io.EOF but got", err)
+	}
+	_, err = r.Read()
+	if err != ErrReaderEnded {
+		t.Error("expected ErrReaderEnded but got", err)
+	}
+}
+
+func TestSetColumnNamesOnHeaderlessDocument(t *testing.T) {
+	lines := []string{
+		`Scott  33  #first`,
+		`Jane   79`,
+	}
+	r := NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	r.SetColumnNames([]string{"Name", "Age"})
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatal("expected 2 lines but got", len(records))
+	}
+	if records[0].IsHeaderLine() {
+		t.Error("expected the first line to be data")
+	}
+	field, err := records[1].Field(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.Value != "79" || field.FieldName != "Age" {
+		t.Errorf("expected 79 in the Age column but got %+v", field)
+	}
+	if idx := r.IndexedAt("Age"); len(idx) != 1 || idx[0] != 1 {
+		t.Error("expected Age to be indexed at 1 but got", idx)
+	}
+}
+
+func TestSetColumnNamesMismatchedCount(t *testing.T) {
+	r := NewReader(strings.NewReader("Scott  33  Male\nJane  79  Female"))
+	r.SetColumnNames([]string{"Name", "Age"})
+	_, err := r.Read()
+	if _, ok := err.(*invalidFieldCountError); !ok {
+		t.Errorf("expected an invalid field count error but got %v", err)
+	}
+}
+
+func TestRoundTripQuoteEscapeStyles(t *testing.T) {
+	rows := [][]string{
+		{`say "hi"`, "x"},
+		{`C:\temp\`, "x"},
+		{`"quoted"`, "x"},
+		{`a\"b`, "x"},
+		{"two\nlines \"here\"", "x"},
+		{"", "a\"b"},
+	}
+	for _, backslash := range []bool{false, true} {
+		d := doc.NewDocument()
+		d.BackslashEscapedQuotes = backslash
+		d.AppendLine(doc.Fields("Value", "Other")...)
+		for _, row := range rows {
+			d.AppendLine(doc.Fields(row...)...)
+		}
+		data, err := d.WriteAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if backslash && !bytes.Contains(data, []byte(`"say \"hi\""`)) {
+			t.Errorf("expected the quotes to be backslash escaped but got\n%s", data)
+		}
+		if !backslash && !bytes.Contains(data, []byte(`"say ""hi"""`)) {
+			t.Errorf("expected the quotes to be doubled but got\n%s", data)
+		}
+
+		r := NewReader(bytes.NewReader(data))
+		r.BackslashEscapedQuotes = backslash
+		lines, err := r.ReadAll()
+		if err != nil {
+			t.Fatalf("backslash escaped %t: %s\n%s", backslash, err, data)
+		}
+		if len(lines) != len(rows)+1 {
+			t.Fatalf("backslash escaped %t: expected %d lines but got %d", backslash, len(rows)+1, len(lines))
+		}
+		for i, row := range rows {
+			for j, v := range row {
+				field, err := lines[i+1].Field(j)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if field.Value != v {
+					t.Errorf("backslash escaped %t: expected [%s] but got [%s]", backslash, v, field.Value)
+				}
+			}
+		}
+	}
+}
+
+func TestBackslashEscapedQuotesStillAcceptDoubledQuotes(t *testing.T) {
+	r := NewReader(strings.NewReader("A  B\n\"say \"\"hi\"\"\"  \"say \\\"bye\\\"\""))
+	r.BackslashEscapedQuotes = true
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := lines[1].Field(0)
+	b, _ := lines[1].Field(1)
+	if a.Value != `say "hi"` || b.Value != `say "bye"` {
+		t.Errorf("expected [say \"hi\"] and [say \"bye\"] but got [%s] and [%s]", a.Value, b.Value)
+	}
+}
+
+func TestReadUTF8BOM(t *testing.T) {
+	data := append([]byte{0xEF, 0xBB, 0xBF}, []byte("Name  Age\nScott  33\n")...)
+	r := NewReader(bytes.NewReader(data))
+	_, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Headers()[0] != "Name" {
+		t.Errorf("expected the first header to be [Name] but got %q", r.Headers()[0])
+	}
+}
+
+func TestReadUTF16WithBOM(t *testing.T) {
+	text := "Name  Flag\nJapan  🇯🇵\nCôte  \"d'Ivoire\"\n"
+	for _, le := range []bool{true, false} {
+		data := []byte{0xFE, 0xFF}
+		if le {
+			data = []byte{0xFF, 0xFE}
+		}
+		for _, u := range utf16.Encode([]rune(text)) {
+			if le {
+				data = append(data, byte(u), byte(u>>8))
+			} else {
+				data = append(data, byte(u>>8), byte(u))
+			}
+		}
+		r := NewReader(bytes.NewReader(data))
+		lines, err := r.ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) != 3 {
+			t.Fatal("expected 3 lines but got", len(lines))
+		}
+		if r.Headers()[0] != "Name" {
+			t.Errorf("expected the first header to be [Name] but got %q", r.Headers()[0])
+		}
+		flag, _ := lines[1].Field(1)
+		if flag.Value != "🇯🇵" {
+			t.Errorf("expected the flag to be decoded but got %q", flag.Value)
+		}
+		country, _ := lines[2].Field(0)
+		if country.Value != "Côte" {
+			t.Errorf("expected the country to be decoded but got %q", country.Value)
+		}
+	}
+}
+
+// an endless UTF-16LE stream of the text `ab`
+type repeatUTF16 struct{}
+
+func (repeatUTF16) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = "a\x00b\x00"[i%4]
+	}
+	return len(p) / 4 * 4, nil
+}
+
+func TestUTF16ReaderReusesBuffers(t *testing.T) {
+	u := &utf16Reader{src: repeatUTF16{}, order: binary.LittleEndian}
+	p := make([]byte, 1000)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := u.Read(p); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Error("expected reads to reuse the buffers but got allocations", allocs)
+	}
+	if !strings.HasPrefix(string(p), "abab") {
+		t.Errorf("expected the stream to be decoded but got %q", p[:4])
+	}
+}
+
+func TestUTF16ReaderSurrogatePairSplitAcrossReads(t *testing.T) {
+	var src []byte
+	for _, unit := range utf16.Encode([]rune("a😀b")) {
+		src = binary.LittleEndian.AppendUint16(src, unit)
+	}
+	// every read of the source returns a single byte so the surrogate pair is split between reads
+	u := &utf16Reader{src: iotest.OneByteReader(bytes.NewReader(src)), order: binary.LittleEndian}
+	data, err := io.ReadAll(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a😀b" {
+		t.Errorf("expected [a😀b] but got %q", data)
+	}
+}
+
+func TestUTF16ReaderTrailingOddByte(t *testing.T) {
+	src := []byte{0x00, 'a', 0x00, 'b', 0x00}
+	u := &utf16Reader{src: bytes.NewReader(src), order: binary.BigEndian}
+	data, err := io.ReadAll(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "ab�" {
+		t.Errorf("expected the odd byte to be decoded as %q but got %q", "ab�", data)
+	}
+}
+
+func TestDocumentEmitBOM(t *testing.T) {
+	d := doc.NewDocument()
+	d.EmitBOM = true
+	d.AppendLine(doc.Fields("Name", "Age")...)
+	d.AppendLine(doc.Fields("Scott", "33")...)
+	data, err := d.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF, 'N'}) {
+		t.Errorf("expected the document to start with a BOM but got %q", data)
+	}
+	r := NewReader(bytes.NewReader(data))
+	_, err = r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Headers()[0] != "Name" {
+		t.Errorf("expected the first header to be [Name] but got %q", r.Headers()[0])
+	}
+}
+
+func TestToDocumentRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("testdata/round-trip.wsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReader(bytes.NewReader(data))
+	d, err := r.ToDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := d.Line(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !header.IsHeader() {
+		t.Error("expected line 3 to be the header")
+	}
+	out, err := d.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, out) {
+		t.Errorf("expected the output to be identical to the input\n%s\nbut got\n%s", data, out)
+	}
+}
+
+func TestNonTabularToDocument(t *testing.T) {
+	r := NewReader(strings.NewReader("a  b\nc  d  e  f\ng"))
+	r.IsTabular = false
+	d, err := r.ToDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := d.Line(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line.FieldCount() != 4 {
+		t.Error("expected line 2 to have 4 fields but got", line.FieldCount())
+	}
+}
+
+func TestInferColumnTypesByLocale(t *testing.T) {
+	tests := []struct {
+		locale NumberLocale
+		data   string
+	}{
+		{NumberLocaleUS, "Item  Price     Note\nA     1,000.50  x\nB     -         y\nC     25        \"1.000,50\"\n"},
+		{NumberLocaleEuropean, "Item  Price     Note\nA     1.000,50  x\nB     -         y\nC     25        \"1,000.50\"\n"},
+	}
+	for _, tt := range tests {
+		r := NewReader(strings.NewReader(tt.data))
+		r.NumberLocale = tt.locale
+		if _, err := r.ReadAll(); err != nil {
+			t.Fatal(err)
+		}
+		types := r.InferColumnTypes()
+		exp := []ColumnType{ColumnTypeString, ColumnTypeNumber, ColumnTypeString}
+		if len(types) != len(exp) {
+			t.Fatal("expected", len(exp), "column types but got", types)
+		}
+		for i := range exp {
+			if types[i] != exp[i] {
+				t.Errorf("locale %d: expected column %d to be %s but got %s", tt.locale, i, exp[i], types[i])
+			}
+		}
+	}
+}
+
+func TestNumberLocaleIsNumber(t *testing.T) {
+	us := []string{"1,000.50", "1000.50", "-12", "+3.5", "12,345,678"}
+	eu := []string{"1.000,50", "1000,50", "-12", "+3,5", "12.345.678"}
+	for _, v := range us {
+		if !NumberLocaleUS.IsNumber(v) {
+			t.Error("expected", v, "to be a US number")
+		}
+	}
+	for _, v := range eu {
+		if !NumberLocaleEuropean.IsNumber(v) {
+			t.Error("expected", v, "to be a European number")
+		}
+	}
+	for _, v := range []string{"1.000,50", "1,00.5", "abc", "", "1,000."} {
+		if NumberLocaleUS.IsNumber(v) {
+			t.Error("expected", v, "not to be a US number")
+		}
+	}
+}
+
+func TestReadAllMaxErrors(t *testing.T) {
+	lines := []string{
+		`Name   Age`,
+		`Scott  33`,
+		`Jane   "79`,
+		`John   41`,
+		`Mary   "12`,
+		`Paul   "50`,
+		`Anne   18`,
+	}
+	r := NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	r.MaxErrors = 2
+	records, err := r.ReadAll()
+	errs, ok := err.(*parseErrorCollection)
+	if !ok {
+		t.Fatal("expected a parse error collection but got", err)
+	}
+	if len(errs.Errs) != 2 {
+		t.Error("expected 2 errors but got", len(errs.Errs))
+	}
+	if len(records) != 5 {
+		t.Fatal("expected the 5 lines read before the limit but got", len(records))
+	}
+	if records[3].LineNumber() != 4 {
+		t.Error("expected the 4th record to be line 4 but got", records[3].LineNumber())
+	}
+
+	r = NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	records, err = r.ReadAll()
+	if errs, ok := err.(*parseErrorCollection); !ok || len(errs.Errs) != 3 {
+		t.Error("expected every error to be collected without a limit but got", err)
+	}
+	if len(records) != 7 {
+		t.Error("expected every line to be read without a limit but got", len(records))
+	}
+}
+
+func TestReadAllEmptyInput(t *testing.T) {
+	for _, input := range []string{"", "   ", "\n", "  \n\t\n"} {
+		r := NewReader(strings.NewReader(input))
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Errorf("%q: expected no error but got %v", input, err)
+		}
+		if records == nil || len(records) != 0 {
+			t.Errorf("%q: expected an empty slice but got %v", input, records)
+		}
+	}
+}
+
+func TestIsHeaderFunc(t *testing.T) {
+	hasText := func(fields []string) bool {
+		for _, field := range fields {
+			if !NumberLocaleUS.IsNumber(field) {
+				return true
+			}
+		}
+		return false
+	}
+
+	r := NewReader(strings.NewReader("1  2.5  3\n4  5    6\n"))
+	r.IsHeaderFunc = hasText
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].IsHeaderLine() {
+		t.Fatal("expected the numeric first line to be read as data")
+	}
+	field, err := records[0].Field(1)
+	if err != nil || field.Value != "2.5" || field.FieldName != "col2" {
+		t.Errorf("expected the field to be named by its position but got %+v", field)
+	}
+	if !r.IncludesHeader {
+		t.Error("expected IncludesHeader to be left as configured")
+	}
+	r.Reset(strings.NewReader("A  B\n3  4\n"))
+	records, err = r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !records[0].IsHeaderLine() || !slices.Equal(r.Headers(), []string{"A", "B"}) {
+		t.Errorf("expected IsHeaderFunc to be called again after a reset but got the headers %v", r.Headers())
+	}
+
+	r = NewReader(strings.NewReader("a  b  c\n4  5  6\n"))
+	r.IsHeaderFunc = hasText
+	records, err = r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !records[0].IsHeaderLine() {
+		t.Error("expected the first line to be the header")
+	}
+	if field, _ := records[1].Field(2); field.FieldName != "c" {
+		t.Error("expected the field to be named c but got", field.FieldName)
+	}
+}
+
+func TestTypedFieldGetters(t *testing.T) {
+	data := "Name   Age  Admin  Joined\nScott  33   True   2024-01-02\nJane   -    nope   -\n"
+	r := NewReader(strings.NewReader(data))
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := records[1]
+	if v, err := line.FieldInt(1); err != nil || v != 33 {
+		t.Error("expected age 33 but got", v, err)
+	}
+	if v, err := line.FieldBool(2); err != nil || !v {
+		t.Error("expected admin to be true but got", v, err)
+	}
+	if v, err := line.FieldTime(3, "dateonly"); err != nil || v.Format(time.DateOnly) != "2024-01-02" {
+		t.Error("expected joined to be 2024-01-02 but got", v, err)
+	}
+	if v, err := line.FieldIntByName("Age"); err != nil || v != 33 {
+		t.Error("expected age 33 by name but got", v, err)
+	}
+	if v, err := line.FieldTimeByName("Joined", "2006-01-02"); err != nil || v.Day() != 2 {
+		t.Error("expected joined by name but got", v, err)
+	}
+	if _, err := line.FieldIntByName("Missing"); err != ErrFieldNotFound {
+		t.Error("expected ErrFieldNotFound but got", err)
+	}
+	if _, err := line.FieldInt(0); err == nil {
+		t.Error("expected an error parsing a name as an int")
+	}
+
+	line = records[2]
+	if _, err := line.FieldInt(1); err != ErrNullField {
+		t.Error("expected ErrNullField but got", err)
+	}
+	if _, err := line.FieldTimeByName("Joined", "dateonly"); err != ErrNullField {
+		t.Error("expected ErrNullField but got", err)
+	}
+	if _, err := line.FieldBoolByName("Admin"); err == nil {
+		t.Error("expected an error parsing nope as a bool")
+	}
+}
+
+func TestLineFieldByName(t *testing.T) {
+	r := NewReader(strings.NewReader("Name   Note   Note\nScott  first  second\n"))
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	field, err := records[1].FieldByName("Note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.Value != "first" || field.FieldIndex != 1 {
+		t.Errorf("expected the first Note column but got %+v", field)
+	}
+	if _, err := records[1].FieldByName("Missing"); err != ErrFieldNotFound {
+		t.Error("expected ErrFieldNotFound but got", err)
+	}
+}
+
+func TestToDocumentPartial(t *testing.T) {
+	lines := []string{
+		`Name   Age`,
+		`Scott  33`,
+		`Jane   "79`,
+		`John   41`,
+	}
+	r := NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	d, errs := r.ToDocumentPartial()
+	if len(errs) != 1 {
+		t.Fatal("expected 1 error but got", errs)
+	}
+	if d.LineCount() != 3 {
+		t.Fatal("expected the 3 parseable lines but got", d.LineCount())
+	}
+	b, err := d.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name   Age\n" +
+		"Scott  33\n" +
+		"John   41\n"
+	if string(b) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(b))
+	}
+}
+
+func TestStrictHeaders(t *testing.T) {
+	data := "Name  Age  Name  City\nScott  33  S  Austin\n"
+	r := NewReader(strings.NewReader(data))
+	if _, err := r.ReadAll(); err != nil {
+		t.Fatal("expected duplicate headers to be allowed by default but got", err)
+	}
+
+	r = NewReader(strings.NewReader(data))
+	r.StrictHeaders = true
+	_, err := r.Read()
+	if !errors.Is(err, ErrDuplicateHeader) {
+		t.Fatal("expected ErrDuplicateHeader but got", err)
+	}
+	exp := "header on line 1: the name Name is used by columns 0, 2"
+	if err.Error() != exp {
+		t.Errorf("expected the error %q but got %q", exp, err.Error())
+	}
+}
+
+func TestIndexSidecar(t *testing.T) {
+	data := "\xEF\xBB\xBF# inventory\nName   Qty\r\nApple  3\n\nPear   \"many, many\"  # note\nPlum   -\n"
+	r := NewReader(strings.NewReader(data))
+	idx, err := r.BuildIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.HeaderLine != 2 || len(idx.Offsets) != 6 {
+		t.Fatalf("expected the header on line 2 and 6 lines but got %+v", idx)
+	}
+
+	var buf bytes.Buffer
+	if _, err := idx.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	idx, err = ReadIndex(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := strings.NewReader(data)
+	line, err := idx.ReadLine(src, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qty, err := line.FieldByName("Qty")
+	if err != nil || qty.Value != "many, many" {
+		t.Errorf("expected the quantity of line 5 to be 'many, many' but got %+v", qty)
+	}
+	if line.LineNumber() != 5 || line.Comment() != " note" {
+		t.Errorf("expected line 5 with a comment but got line %d %q", line.LineNumber(), line.Comment())
+	}
+	line, err = idx.ReadLine(src, 2)
+	if err != nil || !line.IsHeaderLine() {
+		t.Error("expected line 2 to be read as the header", err)
+	}
+	line, err = idx.ReadLine(src, 6)
+	if field, _ := line.Field(1); err != nil || !field.IsNull || field.FieldName != "Qty" {
+		t.Errorf("expected a null Qty on line 6 but got %+v %v", field, err)
+	}
+	if _, err := idx.ReadLine(src, 7); err != ErrLineNotIndexed {
+		t.Error("expected ErrLineNotIndexed but got", err)
+	}
+
+	if _, err := ReadIndex(strings.NewReader("nope")); err != ErrInvalidIndex {
+		t.Error("expected ErrInvalidIndex but got", err)
+	}
+	if _, err := NewReader(io.MultiReader(strings.NewReader(data))).BuildIndex(); err != ErrNotSeekable {
+		t.Error("expected ErrNotSeekable but got", err)
+	}
+}
+
+func TestReaderLines(t *testing.T) {
+	data := "Name   Age\nScott  33\nJane   \"79\nJohn   41\n"
+	r := NewReader(strings.NewReader(data))
+	names := make([]string, 0)
+	errs := 0
+	for line, err := range r.Lines() {
+		if err != nil {
+			errs++
+			continue
+		}
+		field, _ := line.Field(0)
+		names = append(names, field.Value)
+	}
+	if errs != 1 {
+		t.Error("expected 1 error but got", errs)
+	}
+	if strings.Join(names, ",") != "Name,Scott,John" {
+		t.Error("expected every parsed line to be yielded but got", names)
+	}
+
+	r = NewReader(strings.NewReader(data))
+	for range r.Lines() {
+		break
+	}
+	line, err := r.Read()
+	if err != nil || line.LineNumber() != 2 {
+		t.Error("expected to continue reading after breaking from the loop but got", err)
+	}
+
+	errSource := errors.New("source failed")
+	r = NewReader(iotest.ErrReader(errSource))
+	errs = 0
+	for _, err := range r.Lines() {
+		if !errors.Is(err, errSource) {
+			t.Error("expected the error of the source but got", err)
+		}
+		if errs++; errs > 1 {
+			t.Fatal("expected to stop after yielding the error of the source")
+		}
+	}
+}
+
+func TestFieldRange(t *testing.T) {
+	cases := []struct {
+		data             string
+		backslashEscapes bool
+		expected         []string
+	}{
+		{"\"Scott \"\"S\"\"\"  33  # comment", false, []string{`"Scott ""S"""`, "33"}},
+		{`"a ""/"" b"  -`, false, []string{`"a ""/"" b"`, "-"}},
+		{`-  abc`, false, []string{"-", "abc"}},
+		{`x  ""`, false, []string{"x", `""`}},
+		{`"a\\b\"c"  x`, true, []string{`"a\\b\"c"`, "x"}},
+	}
+	for _, c := range cases {
+		r := NewReader(strings.NewReader("Name           Age\n" + c.data + "\n"))
+		r.BackslashEscapedQuotes = c.backslashEscapes
+		if _, err := r.Read(); err != nil {
+			t.Fatal(err)
+		}
+		line, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range c.expected {
+			field, err := line.Field(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start, end := field.Range()
+			if c.data[start:end] != want {
+				t.Errorf("expected field %d of %s to span %s but got [%d, %d) %s", i, c.data, want, start, end, c.data[start:end])
+			}
+		}
+	}
+}
+
+func TestRecordSeparator(t *testing.T) {
+	r := NewReader(strings.NewReader("Name Age\x1eScott 33\x1eJane 79\x1e"))
+	r.RecordSeparator = '\x1e'
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 {
+		t.Fatal("expected 3 records but got", len(lines))
+	}
+	field, err := lines[2].FieldByName("Age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.Value != "79" {
+		t.Error("expected the last record to be read up to its separator but got", field.Value)
+	}
+}
+
+func TestIsDelimiter(t *testing.T) {
+	isDelimiter := func(r rune) bool {
+		return r == ',' || utils.IsFieldDelimiter(r)
+	}
+	r := NewReader(strings.NewReader("Name,Age Note\nScott,33 \"a,b\"\n"))
+	r.IsDelimiter = isDelimiter
+	d, err := r.ToDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(d.Headers(), "|") != "Name|Age|Note" {
+		t.Fatal("expected the comma to separate fields but got", d.Headers())
+	}
+	if err := d.SetPadding([]rune(",")); err != nil {
+		t.Fatal("expected a comma to be valid padding but got", err)
+	}
+	out, err := d.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "Name ,Age,Note\nScott,33 ,\"a,b\"\n"
+	if string(out) != exp {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", exp, string(out))
+	}
+
+	if err := doc.NewDocument().SetPadding([]rune(",")); err == nil {
+		t.Error("expected a comma to be invalid padding with the default delimiters")
+	}
+}
+
+func TestMaxLineBytes(t *testing.T) {
+	data := "Name Note\nScott " + strings.Repeat("a", 10000) + "\nJane short\n"
+	r := NewReader(strings.NewReader(data))
+	r.MaxLineBytes = 100
+	if _, err := r.Read(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := r.Read()
+	if !errors.Is(err, ErrLineTooLong) {
+		t.Fatal("expected ErrLineTooLong but got", err)
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Error("expected the error to include the line number but got", err)
+	}
+	line, err := r.Read()
+	if err != nil {
+		t.Fatal("expected to continue reading after a long line but got", err)
+	}
+	if field, _ := line.FieldByName("Note"); line.LineNumber() != 3 || field.Value != "short" {
+		t.Error("expected the line after the long line but got line", line.LineNumber())
+	}
+
+	r = NewReader(strings.NewReader("Name Note\nScott " + strings.Repeat("a", 120) + "\n"))
+	r.MaxLineBytes = 100
+	r.Read()
+	if _, err := r.Read(); !errors.Is(err, ErrLineTooLong) {
+		t.Error("expected ErrLineTooLong for a line within the read buffer but got", err)
+	}
+}
+
+// cancels the context once the underlying reader is read from
+type cancelingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (c *cancelingReader) Read(p []byte) (int, error) {
+	c.cancel()
+	return c.r.Read(p)
+}
+
+func TestReadAllContext(t *testing.T) {
+	data := "Name Age\nScott 33\nJane 79\n"
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewReader(&cancelingReader{r: strings.NewReader(data), cancel: cancel})
+	lines, err := r.ReadAllContext(ctx)
+	if err != context.Canceled {
+		t.Fatal("expected context.Canceled but got", err)
+	}
+	if len(lines) != 1 {
+		t.Error("expected the line read before the cancellation but got", len(lines))
+	}
+
+	lines, err = NewReader(strings.NewReader(data)).ReadAllContext(context.Background())
+	if err != nil || len(lines) != 3 {
+		t.Error("expected to read every line but got", len(lines), err)
+	}
+}
+
+func TestRoundTripHyphens(t *testing.T) {
+	values := []string{"a-b", "Eremia-Roden", "2024-01-02", "trailing-", "-", "-5", "--", `say "a-b"`}
+	quoted := map[string]bool{"-": true, "-5": true, "--": true, `say "a-b"`: true}
+	d := doc.NewDocument()
+	d.AppendLine(doc.Field("Value"))
+	for _, v := range values {
+		d.AppendLine(doc.Field(v))
+	}
+	data, err := d.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")[1:]
+	for i, v := range values {
+		if strings.HasPrefix(written[i], `"`) != quoted[v] {
+			t.Errorf("expected %s to be quoted %t but was written as %s", v, quoted[v], written[i])
+		}
+	}
+
+	lines, err := NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("%s\n%s", err, data)
+	}
+	for i, v := range values {
+		field, _ := lines[i+1].Field(0)
+		if field.IsNull || field.Value != v {
+			t.Errorf("expected %s to read back the same but got %s", v, field.Value)
+		}
+	}
+}
+
+func TestValidateN(t *testing.T) {
+	lines := []string{"Name Age"}
+	for i := range 10 {
+		lines = append(lines, fmt.Sprintf("Scott%d \"33", i))
+	}
+	data := strings.Join(lines, "\n") + "\n"
+
+	errs := ValidateN(strings.NewReader(data), 3)
+	if len(errs) != 3 {
+		t.Fatal("expected 3 errors but got", len(errs))
+	}
+	var pe *parseError
+	if !errors.As(errs[2], &pe) || pe.Line != 4 {
+		t.Error("expected to stop after the parse error on line 4 but got", errs[2])
+	}
+	if errs := ValidateN(strings.NewReader(data), 0); len(errs) != 10 {
+		t.Error("expected every error without a limit but got", len(errs))
+	}
+	if errs := ValidateN(strings.NewReader("Name Age\nScott 33\n"), 3); len(errs) != 0 {
+		t.Error("expected no errors for a valid document but got", errs)
+	}
+	errSource := errors.New("source failed")
+	if errs := ValidateN(iotest.ErrReader(errSource), 0); len(errs) != 1 || !errors.Is(errs[0], errSource) {
+		t.Error("expected to stop at the error of the source but got", errs)
+	}
+}
+
+func TestPreserveFormatting(t *testing.T) {
+	data := "# people\n" +
+		"Name      Age   \"Home City\"\n" +
+		"\n" +
+		"  Scott   33    Boston    # reviewed\n" +
+		"\"Jane\"    -     \"Denver\"\t \n"
+	r := NewReader(strings.NewReader(data))
+	r.PreserveFormatting = true
+	d, err := r.ToDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := d.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != data {
+		t.Errorf("expected output to be \n%s\nbut got \n%s\ninstead", data, string(out))
+	}
+
+	d.ResetWrite()
+	line, _ := d.Line(4)
+	line.UpdateField(0, "Scotty")
+	out, err = d.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := strings.Replace(data, "  Scott   33", "  Scotty   33", 1)
+	if string(out) != exp {
+		t.Errorf("expected only the edited field to change but got \n%s\ninstead", string(out))
+	}
+
+	r = NewReader(strings.NewReader(data))
+	d, err = r.ToDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ = d.WriteAll()
+	if string(out) == data {
+		t.Error("expected the columns to be aligned without PreserveFormatting")
+	}
+}
+
+func TestHasFooterRow(t *testing.T) {
+	data := "Name Amount\nScott 10\n# middle\nJane 20\nTotal 30\n# end\n"
+	r := NewReader(strings.NewReader(data))
+	r.HasFooterRow = true
+	if r.Footer() != nil {
+		t.Error("expected no footer before reading")
+	}
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	numbers := make([]int, 0, len(lines))
+	for _, line := range lines {
+		numbers = append(numbers, line.LineNumber())
+	}
+	if !slices.Equal(numbers, []int{1, 2, 3, 4, 6}) {
+		t.Error("expected every line but the footer in order but got", numbers)
+	}
+	footer := r.Footer()
+	if footer == nil {
+		t.Fatal("expected a footer")
+	}
+	if field, _ := footer.FieldByName("Amount"); footer.LineNumber() != 5 || field.Value != "30" {
+		t.Error("expected the totals row as the footer but got line", footer.LineNumber())
+	}
+}
+
+func TestCommentColumn(t *testing.T) {
+	data := "Name   Age  Notes\n" +
+		"Scott  33   \"likes tea\"\n" +
+		"Jane   79   -\n" +
+		"John   41   reviewed  # by Eva\n"
+	r := NewReader(strings.NewReader(data))
+	r.CommentColumn = 3
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(r.Headers(), ",") != "Name,Age" {
+		t.Error("expected the comment column to be left out of the headers but got", r.Headers())
+	}
+	comments := []string{"", "likes tea", "", "reviewed by Eva"}
+	for i, line := range lines {
+		if line.FieldCount() != 2 {
+			t.Errorf("expected 2 fields on line %d but got %d", line.LineNumber(), line.FieldCount())
+		}
+		if line.Comment() != comments[i] {
+			t.Errorf("expected the comment on line %d to be %q but got %q", line.LineNumber(), comments[i], line.Comment())
+		}
+	}
+}
+
+func TestNullString(t *testing.T) {
+	data := "Name   Score  City\n" +
+		"Scott  -      NULL\n" +
+		"Jane   -5     \"NULL\"\n"
+	r := NewReader(strings.NewReader(data))
+	r.NullString = "NULL"
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := [][]string{{"Scott", "-", ""}, {"Jane", "-5", "NULL"}}
+	nulls := [][]bool{{false, false, true}, {false, false, false}}
+	for i, line := range lines[1:] {
+		for j, field := range line.Fields() {
+			if field.Value != exp[i][j] || field.IsNull != nulls[i][j] {
+				t.Errorf("expected line %d field %d to be %q null %t but got %q null %t", line.LineNumber(), j, exp[i][j], nulls[i][j], field.Value, field.IsNull)
+			}
+		}
+	}
+
+	r = NewReader(strings.NewReader(data))
+	r.NullString = "NULL"
+	doc, err := r.ToDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := doc.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(d) != data {
+		t.Errorf("expected the document to round trip as \n%s\nbut got \n%s\ninstead", data, string(d))
+	}
+}
+
+func TestReadRecord(t *testing.T) {
+	data := "Name   Age  City\n" +
+		"# people\n" +
+		"Scott  33   \"New York\"\n" +
+		"\n" +
+		"Jane   -    Denver\n"
+	r := NewReader(strings.NewReader(data))
+	exp := []map[string]string{
+		{"Name": "Scott", "Age": "33", "City": "New York"},
+		{"Name": "Jane", "Age": "-", "City": "Denver"},
+	}
+	for _, e := range exp {
+		record, err := r.ReadRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(record, e) {
+			t.Errorf("expected the record to be %v but got %v", e, record)
+		}
+	}
+	if _, err := r.ReadRecord(); err != io.EOF {
+		t.Error("expected io.EOF after the last record but got", err)
+	}
+}
+
+func TestUnescapeControlChars(t *testing.T) {
+	d := doc.NewDocument()
+	d.ControlChars = doc.ControlCharsEscape
+	d.AppendLine(doc.Fields("Name", "Notes")...)
+	d.AppendLine(doc.Field("Scott"), doc.Field("a\tb\x00 \\u0041\x7f"))
+	data, err := d.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReader(bytes.NewReader(data))
+	r.UnescapeControlChars = true
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	field, err := lines[1].FieldByName("Notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.Value != "a\tb\x00 \\u0041\x7f" {
+		t.Errorf("expected the control characters to be decoded but got %q from %q", field.Value, data)
+	}
+}
+
+func TestNullHeaders(t *testing.T) {
+	data := "Name   -   \"\"\n" +
+		"Scott  33  Boston\n"
+	r := NewReader(strings.NewReader(data))
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(r.Headers(), ",") != "Name,col2,col3" {
+		t.Error("expected the null and empty headers to be named by position but got", r.Headers())
+	}
+	field, err := lines[1].FieldByName("col2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.Value != "33" {
+		t.Error("expected col2 to be 33 but got", field.Value)
+	}
+
+	type Person struct {
+		Name string `wsv:"Name"`
+		Age  int    `wsv:"col2"`
+		City string `wsv:"col3"`
+	}
+	var people []Person
+	if err = Unmarshal([]byte(data), &people); err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 1 || people[0] != (Person{"Scott", 33, "Boston"}) {
+		t.Errorf("expected the data to bind to the positional names but got %+v", people)
+	}
+}
+
+func TestReaderReset(t *testing.T) {
+	r := NewReader(strings.NewReader("Name Age\nScott 33\nJane 79\n"))
+	r.NullTrailingColumns = true
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 {
+		t.Fatal("expected 3 lines but got", len(lines))
+	}
+
+	r.Reset(strings.NewReader("\xef\xbb\xbfCity Country Zip\nBoston USA\n"))
+	if !r.NullTrailingColumns || !r.IsTabular || !r.IncludesHeader {
+		t.Error("expected the options to be kept after a reset")
+	}
+	lines, err = r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(r.Headers(), ",") != "City,Country,Zip" {
+		t.Error("expected the headers of the new input but got", r.Headers())
+	}
+	if len(lines) != 2 || lines[1].LineNumber() != 2 {
+		t.Fatalf("expected 2 lines numbered from the start but got %d", len(lines))
+	}
+	zip, err := lines[1].FieldByName("Zip")
+	if err != nil || !zip.IsNull {
+		t.Error("expected the missing trailing column to be null", zip, err)
+	}
+}
+
+func TestReaderResetHeaders(t *testing.T) {
+	next := "A  B\n3  4\n"
+	readNext := func(r *Reader) {
+		t.Helper()
+		r.Reset(strings.NewReader(next))
+		if !r.IncludesHeader {
+			t.Error("expected IncludesHeader to be kept after a reset")
+		}
+		lines, err := r.ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) != 2 || !lines[0].IsHeaderLine() || !slices.Equal(r.Headers(), []string{"A", "B"}) {
+			t.Errorf("expected the header of the next input to be read but got the headers %v", r.Headers())
+		}
+	}
+
+	r := NewReader(strings.NewReader("1  2\n"))
+	r.SetColumnNames([]string{"X", "Y"})
+	if _, err := r.ReadAll(); err != nil {
+		t.Fatal(err)
+	}
+	readNext(r)
+
+	r = NewReader(strings.NewReader("1  2\n"))
+	r.IsHeaderFunc = func(fields []string) bool { return false }
+	if _, err := r.ReadAll(); err != nil {
+		t.Fatal(err)
+	}
+	r.IsHeaderFunc = nil
+	readNext(r)
+}
+
+func TestRoundTripLiteralQuoteSlashQuote(t *testing.T) {
+	values := []string{`"/"`, `say "/" twice`, "line\n\"/\"\nfeed", `/"`}
+	d := doc.NewDocument()
+	d.AppendLine(doc.Fields("Value", "End")...)
+	for _, v := range values {
+		d.AppendLine(doc.Field(v), doc.Field("x"))
+	}
+	data, err := d.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range values {
+		field, err := lines[i+1].FieldByName("Value")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if field.Value != v {
+			t.Errorf("expected %q to round trip but got %q from\n%s", v, field.Value, data)
+		}
+	}
+
+	fields, err := parseLine(1, []byte(`"a"/"b"  """/"""`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields[0].Value != "a\nb" || fields[1].Value != `"/"` {
+		t.Errorf("expected a new line and a literal \"/\" but got %q and %q", fields[0].Value, fields[1].Value)
+	}
+}
+
+func TestReadSections(t *testing.T) {
+	input := "Name Age\nScott 33\nJane 79\n\n\n# cities\nCity Country Zip\nBoston USA 02108\n"
+	r := NewReader(strings.NewReader(input))
+	sections, err := r.ReadSections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 2 {
+		t.Fatal("expected 2 sections but got", len(sections))
+	}
+	if strings.Join(sections[0].Headers(), ",") != "Name,Age" {
+		t.Error("expected the headers of the first section but got", sections[0].Headers())
+	}
+	if strings.Join(sections[1].Headers(), ",") != "City,Country,Zip" {
+		t.Error("expected the headers of the second section but got", sections[1].Headers())
+	}
+	if sections[0].LineCount() != 3 {
+		t.Error("expected 3 lines in the first section but got", sections[0].LineCount())
+	}
+	if sections[1].LineCount() != 3 {
+		t.Error("expected the comment, header and row in the second section but got", sections[1].LineCount())
+	}
+	line, err := sections[1].Line(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zip, err := line.FieldByName("Zip")
+	if err != nil || zip.Value != "02108" {
+		t.Error("expected the zip of the second section to be bound to its header", zip, err)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	r := NewReader(strings.NewReader("Name Age\nScott 33\n"))
+	line, err := r.Peek()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !line.IsHeaderLine() {
+		t.Error("expected to peek the header line")
+	}
+	if again, _ := r.Peek(); again != line {
+		t.Error("expected peeking twice to return the same line")
+	}
+	read, err := r.Read()
+	if err != nil || read != line {
+		t.Fatal("expected to read the peeked line", read, err)
+	}
+	if strings.Join(r.Headers(), ",") != "Name,Age" {
+		t.Error("expected the headers to be registered once but got", r.Headers())
+	}
+
+	line, err = r.Peek()
+	if err != nil {
+		t.Fatal(err)
+	}
+	age, err := line.FieldByName("Age")
+	if err != nil || age.Value != "33" {
+		t.Error("expected to peek the data line", age, err)
+	}
+	if read, _ := r.Read(); read != line {
+		t.Error("expected to read the peeked data line")
+	}
+	if _, err := r.Peek(); err != io.EOF {
+		t.Error("expected to peek io.EOF but got", err)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Error("expected to read the peeked io.EOF but got", err)
+	}
+	if len(r.lines) != 2 {
+		t.Error("expected each line to be kept once but got", len(r.lines))
+	}
+}
+
+func TestMultilineQuotes(t *testing.T) {
+	data := "Name Address Zip\n" +
+		"Scott \"12 Main St\nApt \"\"B\"\"\nBoston\" 02108 # home\n" +
+		"Jane \"1 Elm\"/\"Rd\" 80202\n"
+	r := NewReader(strings.NewReader(data))
+	r.MultilineQuotes = true
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 {
+		t.Fatal("expected 3 lines but got", len(lines))
+	}
+	address, err := lines[1].FieldByName("Address")
+	if err != nil || address.Value != "12 Main St\nApt \"B\"\nBoston" {
+		t.Errorf("expected the address to span 3 lines but got %q %v", address.Value, err)
+	}
+	zip, _ := lines[1].FieldByName("Zip")
+	if zip.Value != "02108" || lines[1].Comment() != " home" {
+		t.Errorf("expected the zip and comment after the closing quote but got %q %q", zip.Value, lines[1].Comment())
+	}
+	if lines[1].LineNumber() != 2 {
+		t.Error("expected the joined line to be numbered by its first physical line but got", lines[1].LineNumber())
+	}
+	address, _ = lines[2].FieldByName("Address")
+	if address.Value != "1 Elm\nRd" || lines[2].LineNumber() != 5 {
+		t.Errorf("expected the next line to read on its own but got %q on line %d", address.Value, lines[2].LineNumber())
+	}
+
+	r = NewReader(strings.NewReader("Name Address Zip\nScott \"a\n/\" 02108\n"))
+	r.MultilineQuotes = true
+	lines, err = r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, _ = lines[1].FieldByName("Address")
+	if address.Value != "a\n/" {
+		t.Errorf("expected the continuation line starting with /\" to be read as is but got %q", address.Value)
+	}
+
+	r = NewReader(strings.NewReader("Name Address\nScott \"12 Main St\nBoston\"\n"))
+	r.Read()
+	if _, err := r.Read(); !errors.Is(err, ErrBareQuote) {
+		t.Error("expected ErrBareQuote without MultilineQuotes but got", err)
+	}
+
+	r = NewReader(strings.NewReader("Name Address\nScott \"12 Main St\nBoston\nUSA\n"))
+	r.MultilineQuotes = true
+	r.MaxLineBytes = 20
+	r.Read()
+	if _, err := r.Read(); !errors.Is(err, ErrLineTooLong) {
+		t.Error("expected the joined line to be limited by MaxLineBytes but got", err)
+	}
+
+	r = NewReader(strings.NewReader("Name Address\nScott \"12 Main St\nBoston\n"))
+	r.MultilineQuotes = true
+	r.Read()
+	var pe *parseError
+	if _, err := r.Read(); !errors.As(err, &pe) || !errors.Is(err, ErrBareQuote) || pe.Line != 2 {
+		t.Error("expected ErrBareQuote on the first physical line for a quote left open at the end of the input but got", err)
+	}
+}
+
+func TestRawColumns(t *testing.T) {
+	d := doc.NewDocument()
+	d.AppendLine(doc.Fields("Name", "Token")...)
+	d.AppendLine(doc.Fields("Scott", `"a b"`)...)
+	d.AppendLine(doc.Fields("Jane", "-")...)
+	d.AppendLine(doc.Field("John"), doc.Null())
+	d.SetRawColumn(1)
+	data, err := d.WriteAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReader(bytes.NewReader(data))
+	r.RawColumns = []int{1}
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{`"a b"`, "-", "-"}
+	for i, v := range exp {
+		token, err := lines[i+1].FieldByName("Token")
+		if err != nil || token.Value != v || token.IsNull {
+			t.Errorf("expected the raw token %s on line %d but got %+v %v", v, i+2, token, err)
+		}
+	}
+	if name, _ := lines[0].Field(1); name.Value != "Token" {
+		t.Error("expected the header to be read as usual but got", name.Value)
+	}
+}
+
+func TestNewReaderFromMmap(t *testing.T) {
+	path := t.TempDir() + "/people.wsv"
+	if err := os.WriteFile(path, []byte("Name Age\nScott 33\nJane 79\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, unmap, err := NewReaderFromMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 {
+		t.Fatal("expected 3 lines but got", len(lines))
+	}
+	age, err := lines[2].FieldByName("Age")
+	if err != nil || age.Value != "79" {
+		t.Error("expected to read the age of the last line", age, err)
+	}
+	if err := unmap(); err != nil {
+		t.Error("expected to unmap the file but got", err)
+	}
+
+	empty := t.TempDir() + "/empty.wsv"
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, unmap, err = NewReaderFromMmap(empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unmap()
+	if _, err := r.Read(); err != io.EOF {
+		t.Error("expected io.EOF for an empty file but got", err)
+	}
+	if _, _, err := NewReaderFromMmap(t.TempDir() + "/missing.wsv"); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected os.ErrNotExist but got", err)
+	}
+}
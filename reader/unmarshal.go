@@ -5,255 +5,184 @@ import (
 	"fmt"
 	"io"
 	"reflect"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/campfhir/wsv/internal"
 )
 
-type unmarshalError struct {
-	field      string
-	format     string
-	comment    bool
-	fieldIndex []int
-	fieldType  string
-	cause      error
-}
+var (
+	ErrMissingColumns = errors.New("struct fields do not have a matching column")
+)
 
 type UnmarshalWSV interface {
 	UnmarshalWSV(value string, format string) error
 }
 
-func (e *unmarshalError) Error() string {
-	if e.cause != nil {
-		return fmt.Sprintf("unmarshal error field: '%s' format: [%s], field index: %d, field type: %s caused by %s", e.field, e.format, e.fieldIndex, e.fieldType, e.cause)
-	}
-	return fmt.Sprintf("unmarshal error field: '%s' format: [%s], field index: %d, field type: %s", e.field, e.format, e.fieldIndex, e.fieldType)
+// Unmarshal a slice of bytes into a struct `v`.
+//
+// Will use the struct tag `wsv` to unmarshal the input. Fields implementing [UnmarshalWSV] are set with it, otherwise fields
+// implementing [encoding.TextUnmarshaler], other than `time.Time`, are set with UnmarshalText.
+//
+// `v` can also be a pointer to a `[]map[string]string`, in which case each data row becomes a map of the
+// header name to the field value. Null fields are omitted from the map, see [UnmarshalNullAs] to record them instead.
+//
+// The elements of the slice can also be pointers to structs, e.g. `[]*Employee`, a new struct is allocated for each row.
+func Unmarshal(d []byte, v any) error {
+	_, err := unmarshal(d, v, unmarshalOptions{})
+	return err
 }
 
-func unmarshalRow(fields []internal.Field, t reflect.Type) (*reflect.Value, error) {
-	val := reflect.New(t).Elem()
-	if val.Kind() != reflect.Struct {
-		return nil, errors.New("expected a struct to unmarshal to")
-	}
-
-	// Collect all fields indexed by tag name
-	tagLookup := make(map[string]fieldInfo)
-	collectFields(val.Type(), nil, tagLookup)
-
-	for _, field := range fields {
-		fi, ok := tagLookup[field.FieldName]
-		if !ok || !fi.Field.IsExported() {
-			continue
-		}
-
-		key, _, format, literalEmptyField := internal.ParseWSVTag(fi.Field)
-		if key == "-" && !literalEmptyField {
+// Unmarshal a slice of bytes into `v` like [Unmarshal] with the options provided.
+func UnmarshalWithOptions(d []byte, v any, options ...UnmarshalOption) error {
+	opts := unmarshalOptions{}
+	for _, option := range options {
+		if option == nil {
 			continue
 		}
-		if key == "" || key != field.FieldName {
-			continue
-		}
-
-		sf := val.FieldByIndex(fi.Index)
+		option(&opts)
+	}
+	_, err := unmarshal(d, v, opts)
+	return err
+}
 
-		// Custom Unmarshaler
-		if sf.CanAddr() {
-			if u, ok := sf.Addr().Interface().(UnmarshalWSV); ok {
-				if err := u.UnmarshalWSV(field.Value, format); err != nil {
-					return nil, newUnmarshalError(key, format, fi.Index, sf.Type().String(), err)
-				}
-				continue
-			}
-		}
+// UnmarshalSkipErrors unmarshals a slice of bytes into a struct `v` like [Unmarshal], but
+// rows with a field that cannot be converted into the struct are skipped instead of failing.
+//
+// Returns the number of rows skipped, an error is only returned for structural problems such as
+// a line that cannot be parsed or `v` not being a slice of structs.
+func UnmarshalSkipErrors(d []byte, v any) (skipped int, err error) {
+	return unmarshal(d, v, unmarshalOptions{skipErrors: true})
+}
 
-		if err := setValue(sf, field, key, format, fi.Index); err != nil {
-			return nil, err
-		}
-	}
+// UnmarshalAll unmarshals a slice of bytes into a struct `v` like [Unmarshal], but continues past rows
+// that cannot be converted into the struct, appending every row that succeeds.
+//
+// Returns an *UnmarshalErrorCollection with an error for each row that failed, an error is returned right away
+// for structural problems such as a line that cannot be parsed or `v` not being a slice of structs.
+func UnmarshalAll(d []byte, v any) error {
+	_, err := unmarshal(d, v, unmarshalOptions{collectErrors: true})
+	return err
+}
 
-	return &val, nil
+// An error converting a row into a struct
+type UnmarshalLineError struct {
+	// the 1-indexed line number of the row
+	Line int
+	Err  error
 }
 
-// setValue assigns a field value according to its kind/pointer type.
-func setValue(sf reflect.Value, field internal.Field, fieldName, format string, idx []int) error {
-	switch sf.Kind() {
-	case reflect.String:
-		sf.SetString(field.Value)
-	case reflect.Bool:
-		format = internal.DefaultIfEmpty(format, "True|False")
-		return setBool(sf, field.Value, fieldName, format, idx)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if _, ok := sf.Interface().(time.Duration); ok {
-			d, err := time.ParseDuration(field.Value)
-			if err != nil {
-				return err
-			}
-			sf.Set(reflect.ValueOf(d))
-			return nil
-		}
-		return setInt(sf, field.Value, fieldName, format, idx)
-	case reflect.Float32, reflect.Float64:
-		return setFloat(sf, field.Value, fieldName, format, idx)
+func (e *UnmarshalLineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
 
-	case reflect.Struct:
-		if _, ok := sf.Interface().(time.Time); ok {
-			format = internal.ParseStructTagDateFormat(format)
-			t, err := time.Parse(format, field.Value)
-			if err != nil {
-				return err
-			}
-			sf.Set(reflect.ValueOf(t))
-			return nil
-		}
-	case reflect.Ptr:
-		if field.IsNull {
-			return nil
-		}
-		return setPointer(sf, field, fieldName, format, idx)
-	default:
-		return newUnmarshalError(fieldName, format, idx, sf.Type().String(), fmt.Errorf("the type '%s' is not supported to unmarshal without [UnmarshalWSV.UnmarshalWSV]", sf.Type().String()))
-	}
-	return nil
+func (e *UnmarshalLineError) Unwrap() error {
+	return e.Err
 }
 
-func setBool(sf reflect.Value, raw, field, format string, idx []int) error {
-	v, err := internal.ParseBool(raw, format)
-	if err != nil {
-		return newUnmarshalError(field, format, idx, sf.Kind().String(), err)
-	}
-	sf.SetBool(v)
-	return nil
+// The errors of every row that failed to unmarshal, see [UnmarshalAll]
+type UnmarshalErrorCollection struct {
+	Errs []*UnmarshalLineError
 }
 
-var intMap = map[string]int64{
-	"base2":  int64(2),
-	"base8":  int64(8),
-	"base10": int64(10),
-	"base16": int64(16),
+func (e *UnmarshalErrorCollection) Error() string {
+	strs := internal.Map(e.Errs, func(x *UnmarshalLineError, i int, a []*UnmarshalLineError) string {
+		return x.Error()
+	})
+	return strings.Join(strs, "\n")
 }
 
-func parseInt(raw, format string) (v int64, err error) {
-	base := int64(10)
-	if b, ok := intMap[format]; ok {
-		base = b
-		format = ""
-	}
-	if format != "" {
-		base, err = strconv.ParseInt(format, 10, strconv.IntSize)
-	}
+// An option to alter the behavior of [UnmarshalWithOptions]
+type UnmarshalOption func(opts *unmarshalOptions)
 
-	if err != nil {
-		return
+// When unmarshalling into a `[]map[string]string`, null fields are recorded with the value `sentinel`
+// rather than being omitted from the map.
+func UnmarshalNullAs(sentinel string) UnmarshalOption {
+	return func(opts *unmarshalOptions) {
+		opts.nullValue = &sentinel
 	}
-	v, err = strconv.ParseInt(raw, int(base), strconv.IntSize)
-	return
 }
 
-func setInt(sf reflect.Value, raw, field string, format string, idx []int) error {
-	v, err := parseInt(raw, format)
-	if err != nil {
-		return newUnmarshalError(field, format, idx, sf.Kind().String(), err)
+// Unmarshal a document without a header line, using `names` as the column names. See [Reader.SetColumnNames]
+func UnmarshalColumnNames(names ...string) UnmarshalOption {
+	return func(opts *unmarshalOptions) {
+		opts.columnNames = names
 	}
-	sf.SetInt(v)
-	return nil
 }
 
-func setFloat(sf reflect.Value, raw, field string, format string, idx []int) error {
-	v, err := strconv.ParseFloat(raw, strconv.IntSize)
-	if err != nil {
-		return newUnmarshalError(field, format, idx, sf.Kind().String(), err)
+// Fail to unmarshal with ErrMissingColumns when a struct field with a `wsv` tag does not have a matching column,
+// by default such fields are left at their zero value.
+func UnmarshalRequireAllFields() UnmarshalOption {
+	return func(opts *unmarshalOptions) {
+		opts.requireAllFields = true
 	}
-	sf.SetFloat(v)
-	return nil
 }
 
-func setPointer(sf reflect.Value, field internal.Field, fieldName, format string, idx []int) error {
-	switch sf.Type().Elem().Kind() {
-	case reflect.String:
-		sf.Set(reflect.ValueOf(&field.Value))
-	case reflect.Bool:
-		format = internal.DefaultIfEmpty(format, "True|False")
-		v, err := internal.ParseBool(field.Value, format)
-		if err != nil {
-			return newUnmarshalError(fieldName, format, idx, "*bool", err)
+// Accept the values in `truthy` and `falsy` as bools, in addition to the `format:` of the field, e.g. for
+// locale specific words such as `oui` and `non`.
+func UnmarshalBoolTokens(truthy []string, falsy []string) UnmarshalOption {
+	return func(opts *unmarshalOptions) {
+		if opts.boolTokens == nil {
+			opts.boolTokens = make(map[string]bool)
 		}
-		sf.Set(reflect.ValueOf(&v))
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		v, err := parseInt(field.Value, format)
-		if err != nil {
-			return newUnmarshalError(fieldName, format, idx, sf.Type().String(), err)
-		}
-		sf.Set(reflect.New(sf.Type().Elem()))
-		sf.Elem().SetInt(v)
-	case reflect.Float32, reflect.Float64:
-		v, err := strconv.ParseFloat(field.Value, 64)
-		if err != nil {
-			return newUnmarshalError(fieldName, format, idx, sf.Type().String(), err)
+		for _, v := range truthy {
+			opts.boolTokens[v] = true
 		}
-		sf.Set(reflect.New(sf.Type().Elem()))
-		sf.Elem().SetFloat(v)
-	default:
-		// Handle special cases like *time.Time
-		if sf.Type() == reflect.TypeOf(&time.Time{}) {
-			v, err := time.Parse(format, field.Value)
-			if err != nil {
-				return newUnmarshalError(fieldName, format, idx, "*time.Time", err)
-			}
-			sf.Set(reflect.ValueOf(&v))
-			return nil
+		for _, v := range falsy {
+			opts.boolTokens[v] = false
 		}
-		return newUnmarshalError(fieldName, format, idx, sf.Type().String(), nil)
 	}
-	return nil
 }
 
-func newUnmarshalError(field string, format string, idx []int, typ string, cause error) error {
-	return &unmarshalError{
-		field:      field,
-		format:     format,
-		fieldIndex: idx,
-		fieldType:  typ,
-		cause:      cause,
+// Trim the leading and trailing white space of values decoded into `string` and `*string` fields
+func UnmarshalTrimStrings() UnmarshalOption {
+	return func(opts *unmarshalOptions) {
+		opts.trimStrings = true
 	}
 }
 
-type fieldInfo struct {
-	Index []int
-	Field reflect.StructField
+type unmarshalOptions struct {
+	// skip rows that fail to convert, counting them rather than returning the error
+	skipErrors bool
+	// continue past rows that fail to convert, collecting their errors
+	collectErrors bool
+	// the value used for null fields when unmarshalling into a map, nil omits the key
+	nullValue *string
+	// column names for a headerless document
+	columnNames []string
+	// every tagged struct field needs a matching column
+	requireAllFields bool
+	// values accepted as bools in addition to the format of the field
+	boolTokens map[string]bool
+	// trim the white space of string fields
+	trimStrings bool
 }
 
-// collectFields flattens all fields (including embedded) with tag info
-func collectFields(t reflect.Type, parentIndex []int, tags map[string]fieldInfo) {
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		idx := append(parentIndex, i)
+var mapStringType = reflect.TypeOf(map[string]string{})
 
-		if f.Anonymous && f.Type.Kind() == reflect.Struct {
-			// recurse into embedded struct
-			collectFields(f.Type, idx, tags)
+// Builds a map of the field name to the field value for a row
+func unmarshalMap(fields []internal.Field, opts unmarshalOptions) reflect.Value {
+	m := make(map[string]string, len(fields))
+	for _, field := range fields {
+		if _, ok := m[field.FieldName]; ok {
+			// duplicate headers bind to the first column, same as [Reader.IndexedAt]
 			continue
 		}
-
-		tag, _ := f.Tag.Lookup("wsv")
-		if tag == "" {
-			tag = f.Name
-		}
-		keys := internal.SplitQuoted(tag)
-		if len(keys) == 0 || keys[0] == "" {
+		if field.IsNull {
+			if opts.nullValue != nil {
+				m[field.FieldName] = *opts.nullValue
+			}
 			continue
 		}
-
-		tags[keys[0]] = fieldInfo{Index: idx, Field: f}
+		m[field.FieldName] = field.Value
 	}
+	return reflect.ValueOf(m)
 }
 
-// Unmarshal a slice of bytes into a struct `v`.
-//
-// Will use the struct tag `wsv` to unmarshal the input.
-func Unmarshal(d []byte, v any) error {
+func unmarshal(d []byte, v any, opts unmarshalOptions) (skipped int, err error) {
 	r := NewReader(strings.NewReader(string(d)))
+	if opts.columnNames != nil {
+		r.SetColumnNames(opts.columnNames)
+	}
 	vt := reflect.TypeOf(v)
 	sl := reflect.ValueOf(v)
 	if vt.Kind() == reflect.Ptr {
@@ -265,8 +194,18 @@ func Unmarshal(d []byte, v any) error {
 	case reflect.Slice:
 		vt = vt.Elem()
 	default:
-		return errors.New("can only unmarshal into a slice")
+		return skipped, errors.New("can only unmarshal into a slice")
+	}
+	// elements of a `[]*T` are allocated for each row
+	pointers := vt.Kind() == reflect.Ptr && vt.Elem().Kind() == reflect.Struct
+	if pointers {
+		vt = vt.Elem()
+	}
+	if vt.Kind() != reflect.Struct && vt != mapStringType {
+		return skipped, errors.New("expected a struct to unmarshal to")
 	}
+	checkedColumns := !opts.requireAllFields || vt == mapStringType
+	errs := make([]*UnmarshalLineError, 0)
 
 	for {
 		rl, err := r.Read()
@@ -274,20 +213,52 @@ func Unmarshal(d []byte, v any) error {
 			break
 		}
 		if err != nil {
-			return err
+			return skipped, err
 		}
 		if rl.IsHeaderLine() {
 			continue
 		}
 
+		// blank and comment-only lines can come before the header, so the columns are checked on the first line with fields
+		if !checkedColumns && rl.FieldCount() > 0 {
+			if missing := internal.MissingColumns(vt, r.headers); len(missing) > 0 {
+				return skipped, fmt.Errorf("%w: %s", ErrMissingColumns, strings.Join(missing, ", "))
+			}
+			checkedColumns = true
+		}
+
 		fields := rl.Fields()
-		val, err := unmarshalRow(fields, vt)
+		if vt == mapStringType {
+			if rl.FieldCount() == 0 {
+				// blank and comment-only lines do not have a record
+				continue
+			}
+			sl.Set(reflect.Append(sl, unmarshalMap(fields, opts)))
+			continue
+		}
+		val, err := internal.UnmarshalRow(fields, vt, internal.RowOptions{BoolTokens: opts.boolTokens, TrimStrings: opts.trimStrings})
+		if err != nil && opts.skipErrors {
+			skipped++
+			continue
+		}
+		if err != nil && opts.collectErrors {
+			errs = append(errs, &UnmarshalLineError{Line: rl.LineNumber(), Err: err})
+			continue
+		}
 		if err != nil {
-			return err
+			return skipped, err
 		}
-		n := reflect.Append(sl, *val)
+		elem := *val
+		if pointers {
+			elem = reflect.New(vt)
+			elem.Elem().Set(*val)
+		}
+		n := reflect.Append(sl, elem)
 		sl.Set(n)
 	}
 
-	return nil
+	if len(errs) > 0 {
+		return skipped, &UnmarshalErrorCollection{Errs: errs}
+	}
+	return skipped, nil
 }
@@ -1,10 +1,14 @@
 package reader_test
 
 import (
+	"errors"
+	"math/big"
+	"net"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/campfhir/wsv/document"
 	"github.com/campfhir/wsv/internal"
 	"github.com/campfhir/wsv/reader"
 )
@@ -166,3 +170,446 @@ func TestUnmarshalSimpleData(t *testing.T) {
 		t.Errorf("expect the first person to buy a home at 2022-09-18 but got '%s'", internal.UnwrapStr(p2.Bought_Home))
 	}
 }
+
+func TestUnmarshalSkipErrors(t *testing.T) {
+	lines := []string{
+		`Name   Age`,
+		`Scott  33`,
+		`Jane   "thirty"`,
+		`John   41`,
+	}
+	data := strings.Join(lines, string('\n'))
+
+	type Person struct {
+		Name string `wsv:"Name"`
+		Age  int    `wsv:"Age"`
+	}
+	var s []Person
+	err := reader.Unmarshal([]byte(data), &s)
+	if err == nil {
+		t.Error("expected Unmarshal to fail on the non-numeric age")
+	}
+
+	s = nil
+	skipped, err := reader.UnmarshalSkipErrors([]byte(data), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped != 1 {
+		t.Error("expected 1 row to be skipped but got", skipped)
+	}
+	if len(s) != 2 {
+		t.Fatal("expect 2 entries in slice but got", len(s))
+	}
+	if s[0].Name != "Scott" || s[0].Age != 33 {
+		t.Errorf("expected the first person to be Scott, 33 but got %+v", s[0])
+	}
+	if s[1].Name != "John" || s[1].Age != 41 {
+		t.Errorf("expected the second person to be John, 41 but got %+v", s[1])
+	}
+}
+
+func TestUnmarshalIntoMap(t *testing.T) {
+	lines := []string{
+		`Name   Age  "Favorite Color"`,
+		`Scott  33   -`,
+		`Jane   -    "Space Purple"`,
+	}
+	data := []byte(strings.Join(lines, string('\n')))
+
+	var s []map[string]string
+	err := reader.Unmarshal(data, &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 {
+		t.Fatal("expect 2 entries in slice but got", len(s))
+	}
+	if s[0]["Name"] != "Scott" || s[0]["Age"] != "33" {
+		t.Errorf("expected the first row to be Scott, 33 but got %+v", s[0])
+	}
+	if _, ok := s[0]["Favorite Color"]; ok {
+		t.Errorf("expected the null favorite color to be omitted but got %+v", s[0])
+	}
+	if s[1]["Favorite Color"] != "Space Purple" {
+		t.Errorf("expected the favorite color to be Space Purple but got %+v", s[1])
+	}
+
+	s = nil
+	err = reader.UnmarshalWithOptions(data, &s, reader.UnmarshalNullAs("NULL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := s[0]["Favorite Color"]; !ok || v != "NULL" {
+		t.Errorf("expected the null favorite color to be NULL but got %+v", s[0])
+	}
+	if s[1]["Age"] != "NULL" {
+		t.Errorf("expected the null age to be NULL but got %+v", s[1])
+	}
+
+	s = nil
+	if err = reader.Unmarshal([]byte("Name Age\n\nAl 3\n# c\n"), &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 1 || s[0]["Name"] != "Al" {
+		t.Errorf("expected blank and comment-only lines to be skipped but got %+v", s)
+	}
+}
+
+func TestUnmarshalWithColumnNames(t *testing.T) {
+	data := []byte("Scott  33\nJane   79")
+	type Person struct {
+		Name string `wsv:"Name"`
+		Age  int    `wsv:"Age"`
+	}
+	var s []Person
+	err := reader.UnmarshalWithOptions(data, &s, reader.UnmarshalColumnNames("Name", "Age"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 {
+		t.Fatal("expect 2 entries in slice but got", len(s))
+	}
+	if s[0].Name != "Scott" || s[0].Age != 33 || s[1].Name != "Jane" || s[1].Age != 79 {
+		t.Errorf("expected Scott, 33 and Jane, 79 but got %+v", s)
+	}
+}
+
+func TestUnmarshalMissingColumns(t *testing.T) {
+	data := []byte("Name   Age\nScott  33\n")
+	type Person struct {
+		Name   string  `wsv:"Name"`
+		Age    int     `wsv:"Age"`
+		City   string  `wsv:"City"`
+		Salary *int    `wsv:"Salary"`
+		Note   string  `wsv:",comment"`
+		Hidden float32 `wsv:"-"`
+	}
+	var s []Person
+	if err := reader.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 1 || s[0].Name != "Scott" || s[0].Age != 33 || s[0].City != "" || s[0].Salary != nil {
+		t.Errorf("expected the unmatched fields to be left at their zero value but got %+v", s)
+	}
+
+	s = nil
+	err := reader.UnmarshalWithOptions(data, &s, reader.UnmarshalRequireAllFields())
+	if !errors.Is(err, reader.ErrMissingColumns) {
+		t.Fatal("expected ErrMissingColumns but got", err)
+	}
+	if !strings.HasSuffix(err.Error(), "City, Salary") {
+		t.Error("expected the missing columns to be listed but got", err)
+	}
+
+	type Complete struct {
+		Name string `wsv:"Name"`
+		Age  int    `wsv:"Age"`
+	}
+	var c []Complete
+	if err := reader.UnmarshalWithOptions(data, &c, reader.UnmarshalRequireAllFields()); err != nil {
+		t.Error("expected every field to be matched but got", err)
+	}
+
+	c = nil
+	if err := reader.UnmarshalWithOptions([]byte("# a leading comment\n\nName Age\nAl 3\n"), &c, reader.UnmarshalRequireAllFields()); err != nil {
+		t.Error("expected the columns to be checked after the header but got", err)
+	}
+	if len(c) == 0 || c[len(c)-1].Name != "Al" || c[len(c)-1].Age != 3 {
+		t.Errorf("expected the data line to be unmarshalled but got %+v", c)
+	}
+}
+
+func TestUnmarshalAllCollectsErrors(t *testing.T) {
+	lines := []string{
+		`Name   Age`,
+		`Scott  33`,
+		`Jane   "thirty"`,
+		`John   41`,
+		`Mary   "old"`,
+	}
+	data := []byte(strings.Join(lines, string('\n')))
+
+	type Person struct {
+		Name string `wsv:"Name"`
+		Age  int    `wsv:"Age"`
+	}
+	var s []Person
+	err := reader.UnmarshalAll(data, &s)
+	errs, ok := err.(*reader.UnmarshalErrorCollection)
+	if !ok {
+		t.Fatal("expected an UnmarshalErrorCollection but got", err)
+	}
+	if len(errs.Errs) != 2 || errs.Errs[0].Line != 3 || errs.Errs[1].Line != 5 {
+		t.Errorf("expected errors on line 3 and 5 but got %v", errs)
+	}
+	if len(s) != 2 || s[0].Name != "Scott" || s[1].Name != "John" {
+		t.Errorf("expected the rows that succeeded to be appended but got %+v", s)
+	}
+
+	s = nil
+	if err := reader.UnmarshalAll([]byte("Name   Age\nScott  33"), &s); err != nil {
+		t.Error("expected no errors but got", err)
+	}
+}
+
+func TestUnmarshalEmbeddedPointer(t *testing.T) {
+	type Audit struct {
+		CreatedBy string     `wsv:"Created By"`
+		CreatedAt *time.Time `wsv:"Created At,format:2006-01-02"`
+	}
+	type Account struct {
+		Name string `wsv:"Name"`
+		*Audit
+	}
+	data := []byte("Name   \"Created By\"  \"Created At\"\nScott  admin         2024-01-02\n")
+	var s []Account
+	if err := reader.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 1 || s[0].Audit == nil {
+		t.Fatalf("expected the embedded pointer to be allocated but got %+v", s)
+	}
+	if s[0].CreatedBy != "admin" || s[0].CreatedAt == nil || s[0].CreatedAt.Format(time.DateOnly) != "2024-01-02" {
+		t.Errorf("expected the embedded fields to be set but got %+v", s[0].Audit)
+	}
+
+	s = nil
+	if err := reader.Unmarshal([]byte("Name\nScott\n"), &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 1 || s[0].Audit != nil {
+		t.Errorf("expected the embedded pointer to stay nil without its columns but got %+v", s)
+	}
+
+	s = nil
+	if err := reader.Unmarshal([]byte("Name   \"Created By\"  \"Created At\"\nScott  -             -\n"), &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 1 || s[0].Audit != nil {
+		t.Errorf("expected the embedded pointer to stay nil when its columns are null but got %+v", s)
+	}
+}
+
+func TestUnmarshalTextUnmarshaler(t *testing.T) {
+	type Host struct {
+		Name    string  `wsv:"Name"`
+		IP      net.IP  `wsv:"IP"`
+		Gateway *net.IP `wsv:"Gateway"`
+	}
+	data := []byte("Name  IP        Gateway\nweb   10.0.0.5  10.0.0.1\ndb    ::1       -\n")
+	var s []Host
+	if err := reader.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 {
+		t.Fatal("expect 2 entries in slice but got", len(s))
+	}
+	if !s[0].IP.Equal(net.ParseIP("10.0.0.5")) || s[0].Gateway == nil || !s[0].Gateway.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("expected the addresses to be parsed but got %+v", s[0])
+	}
+	if !s[1].IP.Equal(net.IPv6loopback) || s[1].Gateway != nil {
+		t.Errorf("expected ::1 and a nil gateway but got %+v", s[1])
+	}
+
+	if err := reader.Unmarshal([]byte("Name  IP\nweb   nope\n"), &s); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+}
+
+func TestUnmarshalBoolTokens(t *testing.T) {
+	lines := []string{
+		`Name   Admin  Active`,
+		`Scott  oui    sí`,
+		`Jane   non    no`,
+		`John   True   -`,
+	}
+	data := []byte(strings.Join(lines, string('\n')))
+	type Person struct {
+		Name   string `wsv:"Name"`
+		Admin  bool   `wsv:"Admin"`
+		Active *bool  `wsv:"Active"`
+	}
+	var s []Person
+	if err := reader.Unmarshal(data, &s); err == nil {
+		t.Error("expected an error without the bool tokens")
+	}
+
+	s = nil
+	err := reader.UnmarshalWithOptions(data, &s, reader.UnmarshalBoolTokens([]string{"oui", "sí"}, []string{"non", "no"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 3 {
+		t.Fatal("expect 3 entries in slice but got", len(s))
+	}
+	if !s[0].Admin || s[0].Active == nil || !*s[0].Active {
+		t.Errorf("expected oui and sí to be true but got %+v", s[0])
+	}
+	if s[1].Admin || s[1].Active == nil || *s[1].Active {
+		t.Errorf("expected non and no to be false but got %+v", s[1])
+	}
+	if !s[2].Admin || s[2].Active != nil {
+		t.Errorf("expected the default True to still be accepted but got %+v", s[2])
+	}
+}
+
+func TestUnmarshalTrimStrings(t *testing.T) {
+	lines := []string{
+		`Name           Nickname`,
+		`"  Scott  "    " Scotty "`,
+	}
+	data := []byte(strings.Join(lines, string('\n')))
+	type Person struct {
+		Name     string  `wsv:"Name"`
+		Nickname *string `wsv:"Nickname"`
+	}
+	var s []Person
+	if err := reader.UnmarshalWithOptions(data, &s, reader.UnmarshalTrimStrings()); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 1 {
+		t.Fatal("expect 1 entry in slice but got", len(s))
+	}
+	if s[0].Name != "Scott" || s[0].Nickname == nil || *s[0].Nickname != "Scotty" {
+		t.Errorf("expected the values to be trimmed but got %+v", s[0])
+	}
+
+	s = nil
+	if err := reader.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s[0].Name != "  Scott  " {
+		t.Errorf("expected the value to be untouched without the option but got %q", s[0].Name)
+	}
+}
+
+func TestUnmarshalPointerTimeShorthand(t *testing.T) {
+	lines := []string{
+		`Name   Dob         Hired`,
+		`Scott  2024-01-02  2024-03-04T05:06:07Z`,
+		`Jane   -           -`,
+	}
+	data := []byte(strings.Join(lines, string('\n')))
+	type Person struct {
+		Name  string     `wsv:"Name"`
+		Dob   *time.Time `wsv:"Dob,format:date"`
+		Hired *time.Time `wsv:"Hired,format:rfc3339"`
+	}
+	var s []Person
+	if err := reader.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 {
+		t.Fatal("expect 2 entries in slice but got", len(s))
+	}
+	if s[0].Dob == nil || !s[0].Dob.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected the date shorthand to parse but got", s[0].Dob)
+	}
+	if s[0].Hired == nil || !s[0].Hired.Equal(time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Error("expected the rfc3339 shorthand to parse but got", s[0].Hired)
+	}
+	if s[1].Dob != nil || s[1].Hired != nil {
+		t.Error("expected null values to leave the pointers nil")
+	}
+}
+
+func TestUnmarshalTimeValues(t *testing.T) {
+	lines := []string{
+		`Name   Created               Timeout`,
+		`Scott  2024-01-02T03:04:05Z  1h30m`,
+		`Jane   -                     -`,
+	}
+	data := []byte(strings.Join(lines, string('\n')))
+	type Job struct {
+		Name    string        `wsv:"Name"`
+		Created time.Time     `wsv:"Created,format:rfc3339"`
+		Timeout time.Duration `wsv:"Timeout"`
+	}
+	var s []Job
+	if err := reader.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 {
+		t.Fatal("expect 2 entries in slice but got", len(s))
+	}
+	if !s[0].Created.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) || s[0].Timeout != 90*time.Minute {
+		t.Errorf("expected the time values to be parsed but got %+v", s[0])
+	}
+	if !s[1].Created.IsZero() || s[1].Timeout != 0 {
+		t.Errorf("expected null values to leave the zero values but got %+v", s[1])
+	}
+
+	s = nil
+	err := reader.Unmarshal([]byte("Name Created\nScott yesterday\n"), &s)
+	if err == nil || !strings.Contains(err.Error(), "Created") {
+		t.Error("expected an unmarshal error for the Created field but got", err)
+	}
+}
+
+func TestUnmarshalPointerElements(t *testing.T) {
+	data := "Name   Age\n" +
+		"Scott  33\n" +
+		"Jane   -\n"
+	type Employee struct {
+		Name string `wsv:"Name"`
+		Age  *int   `wsv:"Age"`
+	}
+	var s []*Employee
+	if err := reader.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 {
+		t.Fatal("expected 2 entries in slice but got", len(s))
+	}
+	if s[0] == nil || s[0].Name != "Scott" || s[0].Age == nil || *s[0].Age != 33 {
+		t.Errorf("expected Scott aged 33 but got %+v", s[0])
+	}
+	if s[1] == nil || s[1].Name != "Jane" || s[1].Age != nil {
+		t.Errorf("expected Jane without an age but got %+v", s[1])
+	}
+	if s[0] == s[1] {
+		t.Error("expected each row to be allocated separately")
+	}
+}
+
+func TestRoundTripBigAndComplex(t *testing.T) {
+	type Measurement struct {
+		Count     big.Int     `wsv:"Count"`
+		Ratio     *big.Float  `wsv:"Ratio"`
+		Impedance complex128  `wsv:"Impedance"`
+		Phase     complex64   `wsv:"Phase"`
+		Signal    *complex128 `wsv:"Signal"`
+	}
+	count, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	signal := complex(-0.5, 1e-9)
+	in := []Measurement{
+		{Count: *count, Ratio: big.NewFloat(0.125), Impedance: complex(3, -4.25), Phase: complex(0, 1), Signal: &signal},
+		{Count: *big.NewInt(-7), Impedance: complex(1e21, 0)},
+	}
+	d, err := document.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []Measurement
+	if err = reader.Unmarshal(d, &out); err != nil {
+		t.Fatalf("%v\n%s", err, d)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("expected %d entries but got %d", len(in), len(out))
+	}
+	for i := range in {
+		if out[i].Count.Cmp(&in[i].Count) != 0 {
+			t.Errorf("expected count %s but got %s", in[i].Count.String(), out[i].Count.String())
+		}
+		if (in[i].Ratio == nil) != (out[i].Ratio == nil) || (in[i].Ratio != nil && out[i].Ratio.Cmp(in[i].Ratio) != 0) {
+			t.Errorf("expected ratio %v but got %v", in[i].Ratio, out[i].Ratio)
+		}
+		if out[i].Impedance != in[i].Impedance || out[i].Phase != in[i].Phase {
+			t.Errorf("expected %v and %v but got %v and %v", in[i].Impedance, in[i].Phase, out[i].Impedance, out[i].Phase)
+		}
+		if (in[i].Signal == nil) != (out[i].Signal == nil) || (in[i].Signal != nil && *out[i].Signal != *in[i].Signal) {
+			t.Errorf("expected signal %v but got %v", in[i].Signal, out[i].Signal)
+		}
+	}
+}
@@ -1,3 +1,3 @@
 #! /bin/sh
 
-go test ./document ./reader
+go test . ./document ./reader